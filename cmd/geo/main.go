@@ -55,10 +55,14 @@ func main() {
 			fmt.Printf("  OpenAPI: %s/openapi.json\n", baseURL)
 			fmt.Println()
 
+			srv.Start()
 			if err := http.ListenAndServe(addr, srv); err != nil {
 				log.Fatalf("Server error: %v", err)
 			}
 		})
+		hooks.OnStop(func() {
+			srv.Close()
+		})
 	})
 
 	cli.Root().Use = "geo"
@@ -123,5 +127,38 @@ func main() {
 	genClientCmd.Flags().StringP("output", "o", "pkg/geoclient", "Output directory for generated client")
 	cli.Root().AddCommand(genClientCmd)
 
+	// pmtiles subcommand: inspect and verify PMTiles archives
+	pmtilesCmd := &cobra.Command{
+		Use:   "pmtiles",
+		Short: "Inspect and verify PMTiles archives",
+	}
+	pmtilesCmd.AddCommand(&cobra.Command{
+		Use:   "inspect <file>",
+		Short: "Print an archive's header, metadata and per-zoom tile statistics",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := inspectPMTiles(os.Stdout, args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error inspecting %s: %v\n", args[0], err)
+				os.Exit(1)
+			}
+		},
+	})
+	pmtilesCmd.AddCommand(&cobra.Command{
+		Use:   "verify <file>",
+		Short: "Check an archive's directories, tiles and header counters",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ok, err := verifyPMTiles(os.Stdout, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error verifying %s: %v\n", args[0], err)
+				os.Exit(1)
+			}
+			if !ok {
+				os.Exit(1)
+			}
+		},
+	})
+	cli.Root().AddCommand(pmtilesCmd)
+
 	cli.Run()
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+)
+
+// inspectPMTiles prints an archive's header, metadata and per-zoom tile
+// counts and size histograms.
+func inspectPMTiles(w io.Writer, path string) error {
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	h := r.Header()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Header\n")
+	fmt.Fprintf(tw, "  spec version\t%d\n", h.SpecVersion)
+	fmt.Fprintf(tw, "  tile type\t%s\n", h.TileType)
+	fmt.Fprintf(tw, "  tile compression\t%s\n", h.TileCompression)
+	fmt.Fprintf(tw, "  internal compression\t%s\n", h.InternalCompression)
+	fmt.Fprintf(tw, "  clustered\t%t\n", h.Clustered)
+	fmt.Fprintf(tw, "  zoom\t%d-%d\n", h.MinZoom, h.MaxZoom)
+	fmt.Fprintf(tw, "  bounds\t%.7f,%.7f,%.7f,%.7f\n", pmtiles.FromE7(h.MinLonE7), pmtiles.FromE7(h.MinLatE7), pmtiles.FromE7(h.MaxLonE7), pmtiles.FromE7(h.MaxLatE7))
+	fmt.Fprintf(tw, "  center\t%.7f,%.7f z%d\n", pmtiles.FromE7(h.CenterLonE7), pmtiles.FromE7(h.CenterLatE7), h.CenterZoom)
+	fmt.Fprintf(tw, "  addressed tiles\t%d\n", h.AddressedTilesCount)
+	fmt.Fprintf(tw, "  tile entries\t%d\n", h.TileEntriesCount)
+	fmt.Fprintf(tw, "  tile contents\t%d\n", h.TileContentsCount)
+	fmt.Fprintf(tw, "  root directory\toffset %d, %d bytes\n", h.RootOffset, h.RootLength)
+	fmt.Fprintf(tw, "  metadata\toffset %d, %d bytes\n", h.MetadataOffset, h.MetadataLength)
+	fmt.Fprintf(tw, "  leaf directories\toffset %d, %d bytes\n", h.LeafDirectoryOffset, h.LeafDirectoryLength)
+	fmt.Fprintf(tw, "  tile data\toffset %d, %d bytes\n", h.TileDataOffset, h.TileDataLength)
+	tw.Flush()
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		return fmt.Errorf("reading metadata: %w", err)
+	}
+	out, err := json.MarshalIndent(metadata, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nMetadata\n  %s\n", out)
+
+	stats, err := r.ZoomStats()
+	if err != nil {
+		return fmt.Errorf("reading directories: %w", err)
+	}
+	fmt.Fprintf(w, "\nZooms\n")
+	fmt.Fprintf(tw, "  zoom\ttiles\tbytes\tavg\tmax")
+	for _, b := range pmtiles.SizeBuckets {
+		fmt.Fprintf(tw, "\t≤%s", pmtiles.FormatSize(int64(b)))
+	}
+	fmt.Fprintf(tw, "\t>%s\n", pmtiles.FormatSize(int64(pmtiles.SizeBuckets[len(pmtiles.SizeBuckets)-1])))
+	for _, s := range stats {
+		fmt.Fprintf(tw, "  %d\t%d\t%s\t%s\t%s", s.Zoom, s.Tiles, pmtiles.FormatSize(int64(s.Bytes)), pmtiles.FormatSize(int64(s.Bytes/s.Tiles)), pmtiles.FormatSize(int64(s.MaxBytes)))
+		for _, n := range s.Sizes {
+			fmt.Fprintf(tw, "\t%d", n)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// verifyPMTiles checks an archive, printing any problems found. It
+// reports whether the archive is valid.
+func verifyPMTiles(w io.Writer, path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := pmtiles.Verify(f, info.Size())
+	if err != nil {
+		return false, err
+	}
+	for _, p := range res.Problems {
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+	if len(res.Problems) > 0 {
+		fmt.Fprintf(w, "%s: INVALID, %d problems (%d tile entries, %d tiles checked)\n", path, len(res.Problems), res.Entries, res.Tiles)
+		return false, nil
+	}
+	fmt.Fprintf(w, "%s: OK (%d tile entries, %d tiles checked)\n", path, res.Entries, res.Tiles)
+	return true, nil
+}
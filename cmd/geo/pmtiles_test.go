@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/plat-geo/internal/tiler"
+	"github.com/joeblew999/plat-geo/internal/tiler/gotiler"
+)
+
+// writePointsArchive tiles two points at zooms 0-3 into dir/points.pmtiles.
+func writePointsArchive(t *testing.T, dir string) string {
+	t.Helper()
+	src := filepath.Join(dir, "points.geojson")
+	err := os.WriteFile(src, []byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"a"},"geometry":{"type":"Point","coordinates":[-122.4,37.8]}},
+{"type":"Feature","properties":{"name":"b"},"geometry":{"type":"Point","coordinates":[151.2,-33.9]}}]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "points.pmtiles")
+	if err := gotiler.New().Tile(context.Background(), src, path, tiler.TileConfig{Layer: "points", MaxZoom: 3}, nil); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInspectPMTiles(t *testing.T) {
+	path := writePointsArchive(t, t.TempDir())
+
+	var out bytes.Buffer
+	if err := inspectPMTiles(&out, path); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`(?m)^  tile type\s+mvt$`,
+		`(?m)^  zoom\s+0-3$`,
+		`(?m)^  bounds\s+-122\.4000000,-33\.9000000,151\.2000000,37\.8000000$`,
+		`"vector_layers"`,
+		`(?m)^  zoom\s+tiles\s+bytes\s+avg\s+max\s+≤1\.0 KB\s+≤4\.0 KB`,
+		`(?m)^  3\s+2\s+`, // two tiles at zoom 3
+	} {
+		if !regexp.MustCompile(want).Match(out.Bytes()) {
+			t.Errorf("output does not match %s:\n%s", want, out.String())
+		}
+	}
+
+	if err := inspectPMTiles(&out, filepath.Join(t.TempDir(), "missing.pmtiles")); err == nil {
+		t.Error("inspected a missing archive")
+	}
+}
+
+func TestVerifyPMTiles(t *testing.T) {
+	dir := t.TempDir()
+	path := writePointsArchive(t, dir)
+
+	var out bytes.Buffer
+	ok, err := verifyPMTiles(&out, path)
+	if err != nil || !ok || !strings.Contains(out.String(), path+": OK") {
+		t.Errorf("valid archive: ok %v, err %v, output %q", ok, err, out.String())
+	}
+
+	// Bytes 72-79 of the header hold the addressed tile count
+	archive, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive[72]++
+	miscounted := filepath.Join(dir, "miscounted.pmtiles")
+	if err := os.WriteFile(miscounted, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	ok, err = verifyPMTiles(&out, miscounted)
+	if err != nil || ok || !strings.Contains(out.String(), "header addresses") || !strings.Contains(out.String(), miscounted+": INVALID, 1 problems") {
+		t.Errorf("miscounted archive: ok %v, err %v, output %q", ok, err, out.String())
+	}
+
+	garbage := filepath.Join(dir, "garbage.pmtiles")
+	if err := os.WriteFile(garbage, []byte("not an archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := verifyPMTiles(&out, garbage); err == nil || ok {
+		t.Errorf("garbage: ok %v, err %v; want an error", ok, err)
+	}
+}
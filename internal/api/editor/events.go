@@ -13,13 +13,15 @@ import (
 type EventHandler struct {
 	humastar.Handler
 	layerService *service.LayerService
+	tileService  *service.TileService
 }
 
 // NewEventHandler creates a new event handler.
-func NewEventHandler(layerService *service.LayerService, renderer *humastar.Renderer) *EventHandler {
+func NewEventHandler(layerService *service.LayerService, tileService *service.TileService, renderer *humastar.Renderer) *EventHandler {
 	return &EventHandler{
 		Handler:      humastar.Handler{Renderer: renderer},
 		layerService: layerService,
+		tileService:  tileService,
 	}
 }
 
@@ -48,6 +50,15 @@ func (h *EventHandler) Events(ctx context.Context, input *humastar.EmptyInput) (
 							layerService: h.layerService,
 						}
 						sse.Patch(lh.renderLayerList(h.layerService.List()), "#layer-list")
+					case "tiles":
+						th := &TileHandler{
+							Handler:     humastar.Handler{Renderer: h.Renderer},
+							tileService: h.tileService,
+						}
+						if tiles, err := h.tileService.List(); err == nil {
+							sse.Patch(th.renderTileList(tiles), "#tile-list")
+							sse.Patch(th.renderTileSelect(tiles), "#pmtiles-select")
+						}
 					}
 					sse.DispatchCustomEvent("resource-changed", map[string]any{
 						"resource": ev.Resource,
@@ -103,7 +103,7 @@ func (h *LayerHandler) renderLayerList(layers map[string]service.LayerConfig) st
 	items := make([]any, 0, len(layers))
 	for id, layer := range layers {
 		configJSON, _ := json.Marshal(map[string]any{
-			"file": layer.File, "pmtilesLayer": layer.PMTilesLayer,
+			"file": layer.File, "version": layer.Version, "pmtilesLayer": layer.PMTilesLayer,
 			"geomType": layer.GeomType, "fill": layer.Fill,
 			"stroke": layer.Stroke, "opacity": layer.Opacity,
 		})
@@ -10,6 +10,7 @@ import (
 var LayerConfigSignalNames = struct {
 	Name           string
 	File           string
+	Version        string
 	PMTilesLayer   string
 	GeomType       string
 	DefaultVisible string
@@ -20,6 +21,7 @@ var LayerConfigSignalNames = struct {
 }{
 	Name:           "newlayername",
 	File:           "newlayerfile",
+	Version:        "newlayerversion",
 	PMTilesLayer:   "newlayerpmtileslayer",
 	GeomType:       "newlayergeomtype",
 	DefaultVisible: "newlayervisible",
@@ -33,6 +35,7 @@ func ParseLayerConfigSignals(s humastar.Signals) service.LayerConfig {
 	return service.LayerConfig{
 		Name:           s.String("newlayername"),
 		File:           s.String("newlayerfile"),
+		Version:        s.String("newlayerversion"),
 		PMTilesLayer:   s.String("newlayerpmtileslayer"),
 		GeomType:       s.String("newlayergeomtype"),
 		DefaultVisible: s.Bool("newlayervisible"),
@@ -47,6 +50,7 @@ func ResetLayerConfigSignals() map[string]any {
 	return map[string]any{
 		"newlayername":         "",
 		"newlayerfile":         "",
+		"newlayerversion":      "",
 		"newlayerpmtileslayer": "default",
 		"newlayergeomtype":     "polygon",
 		"newlayervisible":      true,
@@ -118,7 +118,7 @@ func (h *SourceHandler) renderSourceList(sources []service.SourceFile) string {
 	for i, s := range sources {
 		items[i] = SourceCardData{Name: s.Name, Size: s.Size, FileType: s.FileType}
 	}
-	return h.RenderList("source-card", items, "No Source Files", "Upload GeoJSON or GeoParquet files using the form above.")
+	return h.RenderList("source-card", items, "No Source Files", "Upload GeoJSON, GeoParquet or FlatGeobuf files using the form above.")
 }
 
 func (h *SourceHandler) renderSourceSelect(sources []service.SourceFile) string {
@@ -3,6 +3,8 @@ package editor
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 
@@ -10,17 +12,20 @@ import (
 	"github.com/joeblew999/plat-geo/internal/service"
 )
 
+// jobPollInterval is how often Generate reports a job's progress.
+const jobPollInterval = 500 * time.Millisecond
+
 type TileHandler struct {
 	humastar.Handler
-	tileService  *service.TileService
-	tilerService *service.TilerService
+	tileService *service.TileService
+	jobService  *service.JobService
 }
 
-func NewTileHandler(tileService *service.TileService, tilerService *service.TilerService, renderer *humastar.Renderer) *TileHandler {
+func NewTileHandler(tileService *service.TileService, jobService *service.JobService, renderer *humastar.Renderer) *TileHandler {
 	return &TileHandler{
-		Handler:      humastar.Handler{Renderer: renderer},
-		tileService:  tileService,
-		tilerService: tilerService,
+		Handler:     humastar.Handler{Renderer: renderer},
+		tileService: tileService,
+		jobService:  jobService,
 	}
 }
 
@@ -28,6 +33,29 @@ func (h *TileHandler) RegisterRoutes(api huma.API) {
 	huma.Get(api, "/api/v1/editor/tiles", h.ListTiles, huma.OperationTags("editor"))
 	huma.Get(api, "/api/v1/editor/tiles/select", h.ListTilesSelect, huma.OperationTags("editor"))
 	huma.Post(api, "/api/v1/editor/tiles/generate", h.Generate, huma.OperationTags("editor"))
+	huma.Post(api, "/api/v1/editor/tiles/autofill", h.Autofill, huma.OperationTags("editor"))
+}
+
+// Autofill sets the new layer's PMTiles layer and geometry type from the
+// selected file's first vector layer.
+func (h *TileHandler) Autofill(ctx context.Context, input *humastar.SignalsInput) (*huma.StreamResponse, error) {
+	signals, err := input.MustParse()
+	if err != nil {
+		return nil, err
+	}
+	file, err := h.tileService.Get(signals.String(LayerConfigSignalNames.File))
+	if err != nil || len(file.Layers) == 0 {
+		return h.Stream(func(sse humastar.SSE) {}), nil
+	}
+
+	return h.Stream(func(sse humastar.SSE) {
+		layer := file.Layers[0]
+		updates := map[string]any{LayerConfigSignalNames.PMTilesLayer: layer.Name}
+		if layer.GeomType != "" {
+			updates[LayerConfigSignalNames.GeomType] = layer.GeomType
+		}
+		sse.Signals(updates)
+	}), nil
 }
 
 func (h *TileHandler) Generate(ctx context.Context, input *humastar.SignalsInput) (*huma.StreamResponse, error) {
@@ -42,6 +70,9 @@ func (h *TileHandler) Generate(ctx context.Context, input *humastar.SignalsInput
 		LayerName:  signals.String("layername"),
 		MinZoom:    signals.Int("minzoom"),
 		MaxZoom:    signals.Int("maxzoom"),
+		Engine:     signals.String("engine"),
+		Include:    splitList(signals.String("include")),
+		Exclude:    splitList(signals.String("exclude")),
 	}
 	if opts.SourceFile == "" {
 		return nil, huma.Error400BadRequest("Source file is required")
@@ -54,26 +85,52 @@ func (h *TileHandler) Generate(ctx context.Context, input *humastar.SignalsInput
 		Body: func(humaCtx huma.Context) {
 			sse := humastar.NewSSE(humaCtx)
 
-			if h.tilerService == nil {
-				sse.Error("Tiler service not configured")
+			if h.jobService == nil {
+				sse.Error("Job service not configured")
 				return
 			}
 
-			sse.ConsoleLogf("Starting tile generation: %s → %s", opts.SourceFile, opts.OutputName)
+			// The job runs in the background, so closing the page only
+			// stops these progress updates
+			job, err := h.jobService.Submit(service.JobRequest{Generate: &opts})
+			if err != nil {
+				sse.ConsoleError(err)
+				sse.Error(err.Error())
+				return
+			}
+			sse.ConsoleLogf("Starting tile generation job %s: %s → %s", job.ID, opts.SourceFile, opts.OutputName)
 
-			err := h.tilerService.Generate(ctx, opts, func(progress int, status string) {
-				sse.Signals(map[string]any{
-					"tileStatus":   status,
-					"tileProgress": progress,
-				})
+			ticker := time.NewTicker(jobPollInterval)
+			defer ticker.Stop()
+			for !job.State.Finished() {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+				var ok bool
+				if job, ok = h.jobService.Get(job.ID); !ok {
+					sse.Error("Tile generation job disappeared")
+					return
+				}
+				if job.Status != "" {
+					sse.Signals(map[string]any{
+						"tileStatus":   job.Status,
+						"tileProgress": job.Progress,
+					})
+				}
 				if sse.IsClosed() {
 					return
 				}
-			})
+			}
 
-			if err != nil {
-				sse.ConsoleError(err)
-				sse.Error(err.Error())
+			if job.State != service.JobSucceeded {
+				msg := job.Error
+				if msg == "" {
+					msg = "Tile generation " + string(job.State)
+				}
+				sse.ConsoleLogf("Tile generation job %s %s", job.ID, job.State)
+				sse.Error(msg)
 				return
 			}
 
@@ -121,14 +178,19 @@ func (h *TileHandler) ListTilesSelect(ctx context.Context, input *humastar.Empty
 type TileCardData struct {
 	Name string
 	Size string
+	Info string
 }
 
 func (h *TileHandler) renderTileList(tiles []service.TileFile) string {
 	items := make([]any, len(tiles))
 	for i, t := range tiles {
-		items[i] = TileCardData{Name: t.Name, Size: t.Size}
+		card := TileCardData{Name: t.Name, Size: t.Size}
+		if t.TileType != "" {
+			card.Info = fmt.Sprintf("%s, z%d-%d, %d layers", t.TileType, t.MinZoom, t.MaxZoom, len(t.Layers))
+		}
+		items[i] = card
 	}
-	return h.RenderList("tile-card", items, "No PMTiles Found", "Upload GeoJSON files and generate tiles, or add .pmtiles files to .data/tiles/")
+	return h.RenderList("tile-card", items, "No PMTiles Found", "Generate tiles from a source file, or upload an existing .pmtiles archive below.")
 }
 
 func (h *TileHandler) renderTileSelect(tiles []service.TileFile) string {
@@ -138,3 +200,14 @@ func (h *TileHandler) renderTileSelect(tiles []service.TileFile) string {
 	}
 	return h.RenderSelect("-- Select a PMTiles file --", opts)
 }
+
+// splitList parses a comma-separated form field, ignoring blank entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
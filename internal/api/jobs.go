@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/joeblew999/plat-geo/internal/humastar"
+	"github.com/joeblew999/plat-geo/internal/service"
+)
+
+type JobIDInput struct {
+	ID string `path:"id" doc:"Job ID" example:"20260101-120000-a1b2c3"`
+}
+
+// JobBody wraps a Job with state-dependent hypermedia actions.
+type JobBody struct {
+	service.Job
+}
+
+// Actions implements humastar.Actor — running work can be cancelled,
+// failed or cancelled work retried, and finished output followed.
+func (b JobBody) Actions() []humastar.Action {
+	var actions []humastar.Action
+	switch b.State {
+	case service.JobQueued, service.JobRunning:
+		actions = append(actions, humastar.Action{
+			Rel: "cancel", Href: fmt.Sprintf("/api/v1/jobs/%s/cancel", b.ID),
+			Method: "POST", Title: "Cancel",
+		})
+	case service.JobFailed, service.JobCancelled:
+		actions = append(actions, humastar.Action{
+			Rel: "retry", Href: fmt.Sprintf("/api/v1/jobs/%s/retry", b.ID),
+			Method: "POST", Title: "Retry",
+		})
+	case service.JobSucceeded:
+		actions = append(actions, humastar.Action{
+			Rel: "related", Href: "/api/v1/tiles", Title: "Tile Files",
+		})
+	}
+	return actions
+}
+
+type JobOutput struct {
+	Body JobBody
+}
+
+// RegisterJobs registers background job routes.
+func (h *APIHandler) RegisterJobs(api huma.API) {
+	huma.Get(api, "/api/v1/jobs", h.GetJobs, huma.OperationTags("jobs"))
+	huma.Post(api, "/api/v1/jobs", h.SubmitJob, huma.OperationTags("jobs"), func(o *huma.Operation) {
+		o.DefaultStatus = http.StatusAccepted
+	})
+	huma.Get(api, "/api/v1/jobs/{id}", h.GetJob, huma.OperationTags("jobs"))
+	huma.Post(api, "/api/v1/jobs/{id}/cancel", h.CancelJob, huma.OperationTags("jobs"))
+	huma.Post(api, "/api/v1/jobs/{id}/retry", h.RetryJob, huma.OperationTags("jobs"))
+}
+
+func (h *APIHandler) GetJobs(ctx context.Context, input *ListInput) (*struct {
+	Body humastar.PageBody[JobBody]
+}, error) {
+	if h.svc == nil || h.svc.Job == nil {
+		return &struct{ Body humastar.PageBody[JobBody] }{}, nil
+	}
+	jobs, total := h.svc.Job.ListPaged(input.Offset, input.Limit)
+	items := make([]JobBody, len(jobs))
+	for i, job := range jobs {
+		items[i] = JobBody{job}
+	}
+	return &struct{ Body humastar.PageBody[JobBody] }{Body: humastar.PageBody[JobBody]{
+		Total: total, Offset: input.Offset, Limit: input.Limit,
+		Data: items,
+	}}, nil
+}
+
+func (h *APIHandler) SubmitJob(ctx context.Context, input *struct{ Body service.JobRequest }) (*JobOutput, error) {
+	if h.svc == nil || h.svc.Job == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	job, err := h.svc.Job.Submit(input.Body)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	return &JobOutput{Body: JobBody{job}}, nil
+}
+
+func (h *APIHandler) GetJob(ctx context.Context, input *JobIDInput) (*JobOutput, error) {
+	if h.svc == nil || h.svc.Job == nil {
+		return nil, huma.Error404NotFound("service not available")
+	}
+	job, ok := h.svc.Job.Get(input.ID)
+	if !ok {
+		return nil, huma.Error404NotFound("job not found")
+	}
+	return &JobOutput{Body: JobBody{job}}, nil
+}
+
+func (h *APIHandler) CancelJob(ctx context.Context, input *JobIDInput) (*JobOutput, error) {
+	if h.svc == nil || h.svc.Job == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	if _, ok := h.svc.Job.Get(input.ID); !ok {
+		return nil, huma.Error404NotFound("job not found")
+	}
+	job, err := h.svc.Job.Cancel(input.ID)
+	if err != nil {
+		return nil, huma.Error409Conflict(err.Error())
+	}
+	return &JobOutput{Body: JobBody{job}}, nil
+}
+
+func (h *APIHandler) RetryJob(ctx context.Context, input *JobIDInput) (*JobOutput, error) {
+	if h.svc == nil || h.svc.Job == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	if _, ok := h.svc.Job.Get(input.ID); !ok {
+		return nil, huma.Error404NotFound("job not found")
+	}
+	job, err := h.svc.Job.Retry(input.ID)
+	if err != nil {
+		return nil, huma.Error409Conflict(err.Error())
+	}
+	return &JobOutput{Body: JobBody{job}}, nil
+}
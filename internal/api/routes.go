@@ -4,6 +4,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/joeblew999/plat-geo/internal/humastar"
@@ -15,6 +16,8 @@ type Services struct {
 	Layer  *service.LayerService
 	Tile   *service.TileService
 	Source *service.SourceService
+	Tiler  *service.TilerService
+	Job    *service.JobService
 }
 
 // Types
@@ -128,9 +131,25 @@ func (h *APIHandler) RegisterSources(api huma.API) {
 	huma.Get(api, "/api/v1/sources", h.GetSources, huma.OperationTags("sources"))
 }
 
-// RegisterTiles registers tile listing routes.
+// RegisterTiles registers tile management and versioning routes.
 func (h *APIHandler) RegisterTiles(api huma.API) {
 	huma.Get(api, "/api/v1/tiles", h.GetTiles, huma.OperationTags("tiles"))
+	huma.Post(api, "/api/v1/tiles", h.UploadTile, huma.OperationTags("tiles"), func(o *huma.Operation) {
+		o.DefaultStatus = http.StatusCreated
+		o.RequestBody = tileUploadBody
+	})
+	huma.Get(api, "/api/v1/tiles/{name}", h.GetTile, huma.OperationTags("tiles"))
+	huma.Delete(api, "/api/v1/tiles/{name}", h.DeleteTile, huma.OperationTags("tiles"))
+	huma.Post(api, "/api/v1/tiles/{name}/rename", h.RenameTile, huma.OperationTags("tiles"))
+	huma.Get(api, "/api/v1/tiles/{name}/tilejson.json", h.GetTileJSON, huma.OperationTags("tiles"))
+	huma.Post(api, "/api/v1/tiles/{name}/verify", h.VerifyTile, huma.OperationTags("tiles"))
+	huma.Get(api, "/api/v1/tiles/{name}/versions", h.GetTileVersions, huma.OperationTags("tiles"))
+	huma.Post(api, "/api/v1/tiles/{name}/versions/{version}/rollback", h.RollbackTileVersion, huma.OperationTags("tiles"))
+}
+
+// RegisterEngines registers tiler engine listing routes.
+func (h *APIHandler) RegisterEngines(api huma.API) {
+	huma.Get(api, "/api/v1/engines", h.GetEngines, huma.OperationTags("tiles"))
 }
 
 // Handlers
@@ -150,6 +169,9 @@ func (h *APIHandler) CreateLayer(ctx context.Context, input *struct{ Body servic
 	if h.svc == nil || h.svc.Layer == nil {
 		return nil, huma.Error400BadRequest("service not available")
 	}
+	if err := h.validatePin(input.Body); err != nil {
+		return nil, err
+	}
 	created, err := h.svc.Layer.Create(input.Body)
 	if err != nil {
 		return nil, huma.Error400BadRequest(err.Error())
@@ -177,6 +199,9 @@ func (h *APIHandler) PutLayer(ctx context.Context, input *struct {
 	if h.svc == nil || h.svc.Layer == nil {
 		return nil, huma.Error400BadRequest("service not available")
 	}
+	if err := h.validatePin(input.Body); err != nil {
+		return nil, err
+	}
 	updated, err := h.svc.Layer.Update(input.ID, input.Body)
 	if err != nil {
 		return nil, huma.Error404NotFound(err.Error())
@@ -242,6 +267,13 @@ func (h *APIHandler) GetTiles(ctx context.Context, input *ListInput) (*struct {
 	}}, nil
 }
 
+func (h *APIHandler) GetEngines(ctx context.Context, input *struct{}) (*struct{ Body []service.TilerEngine }, error) {
+	if h.svc == nil || h.svc.Tiler == nil {
+		return &struct{ Body []service.TilerEngine }{Body: []service.TilerEngine{}}, nil
+	}
+	return &struct{ Body []service.TilerEngine }{Body: h.svc.Tiler.Engines()}, nil
+}
+
 func (h *APIHandler) PublishLayer(ctx context.Context, input *IDInput) (*LayerOutput, error) {
 	if h.svc == nil || h.svc.Layer == nil {
 		return nil, huma.Error400BadRequest("service not available")
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/joeblew999/plat-geo/internal/humastar"
+	"github.com/joeblew999/plat-geo/internal/service"
+)
+
+type TileNameInput struct {
+	Name string `path:"name" doc:"PMTiles file name" example:"buildings.pmtiles"`
+}
+
+// TileJSONInput resolves the scheme and host tile URLs are served from.
+type TileJSONInput struct {
+	TileNameInput
+	ForwardedProto string `header:"X-Forwarded-Proto" doc:"Scheme used by the client when behind a proxy"`
+	baseURL        string
+}
+
+func (i *TileJSONInput) Resolve(ctx huma.Context) []error {
+	scheme := "http"
+	if ctx.TLS() != nil {
+		scheme = "https"
+	}
+	if i.ForwardedProto != "" {
+		scheme, _, _ = strings.Cut(i.ForwardedProto, ",")
+	}
+	i.baseURL = scheme + "://" + ctx.Host()
+	return nil
+}
+
+// TileFileBody wraps a TileFile with hypermedia actions.
+type TileFileBody struct {
+	service.TileFile
+}
+
+// tileFileActions defines the action templates for tileset resources.
+var tileFileActions = []humastar.ActionDef{
+	{Rel: "rename", Pattern: "/api/v1/tiles/%s/rename", Method: "POST", Title: "Rename", Schema: "/schemas/RenameTileInput.json"},
+	{Rel: "verify", Pattern: "/api/v1/tiles/%s/verify", Method: "POST", Title: "Verify"},
+	{Rel: "delete", Pattern: "/api/v1/tiles/%s", Method: "DELETE", Title: "Delete"},
+}
+
+// Actions implements humastar.Actor — links a tileset to its TileJSON,
+// versions and archive.
+func (b TileFileBody) Actions() []humastar.Action {
+	return append(humastar.ActionsFor(b.Name, tileFileActions),
+		humastar.Action{Rel: "describedby", Href: fmt.Sprintf("/api/v1/tiles/%s/tilejson.json", b.Name), Title: "TileJSON"},
+		humastar.Action{Rel: "version-history", Href: fmt.Sprintf("/api/v1/tiles/%s/versions", b.Name), Title: "Versions"},
+		humastar.Action{Rel: "enclosure", Href: "/tiles/" + b.Name, Title: "Download"},
+	)
+}
+
+type TileDeleteInput struct {
+	TileNameInput
+	Force bool `query:"force" default:"false" doc:"Delete even if layers use the file"`
+}
+
+type RenameTileInput struct {
+	Name string `json:"name" required:"true" minLength:"1" maxLength:"200" doc:"New PMTiles file name" example:"buildings-2024.pmtiles"`
+}
+
+// TileUploadInput streams a multipart upload rather than buffering the
+// form, since archives can be far larger than the request body limit.
+type TileUploadInput struct {
+	ContentType string `header:"Content-Type" doc:"multipart/form-data with a file field and an optional name field"`
+	body        io.Reader
+}
+
+func (i *TileUploadInput) Resolve(ctx huma.Context) []error {
+	i.body = ctx.BodyReader()
+	return nil
+}
+
+// tileUploadBody documents the multipart form read by TileUploadInput.
+var tileUploadBody = &huma.RequestBody{
+	Required: true,
+	Content: map[string]*huma.MediaType{
+		"multipart/form-data": {Schema: &huma.Schema{
+			Type: "object",
+			Properties: map[string]*huma.Schema{
+				"name": {Type: "string", Description: "File name to store the archive as; defaults to the uploaded file's name"},
+				"file": {Type: "string", Format: "binary", Description: "PMTiles archive"},
+			},
+			Required: []string{"file"},
+		}},
+	},
+}
+
+type TileFileOutput struct {
+	Body TileFileBody
+}
+
+// TileJSONBody wraps a TileJSON with a link back to its tileset.
+type TileJSONBody struct {
+	service.TileJSON
+	file string
+}
+
+// Actions implements humastar.Actor.
+func (b TileJSONBody) Actions() []humastar.Action {
+	return []humastar.Action{
+		{Rel: "related", Href: "/api/v1/tiles/" + b.file, Title: "Tile File"},
+	}
+}
+
+// TileVerificationBody wraps a TileVerification with a link back to its tileset.
+type TileVerificationBody struct {
+	service.TileVerification
+}
+
+// Actions implements humastar.Actor.
+func (b TileVerificationBody) Actions() []humastar.Action {
+	return []humastar.Action{
+		{Rel: "related", Href: "/api/v1/tiles/" + b.Name, Title: "Tile File"},
+	}
+}
+
+type TileVersionInput struct {
+	TileNameInput
+	Version string `path:"version" doc:"Version ID" example:"3f9a1c2b7d4e"`
+}
+
+// TileVersionBody wraps a TileVersion with state-dependent hypermedia actions.
+type TileVersionBody struct {
+	service.TileVersion
+	name string
+}
+
+// Actions implements humastar.Actor — any version but the current one can
+// be rolled back to, and any version can be pinned by a layer created
+// with its file and version.
+func (b TileVersionBody) Actions() []humastar.Action {
+	var actions []humastar.Action
+	if !b.Current {
+		actions = append(actions, humastar.Action{
+			Rel: "rollback", Href: fmt.Sprintf("/api/v1/tiles/%s/versions/%s/rollback", b.name, b.ID),
+			Method: "POST", Title: "Roll Back",
+		})
+	}
+	actions = append(actions,
+		humastar.Action{Rel: "pin", Href: "/api/v1/layers", Method: "POST", Title: "Pin in a Layer", Schema: "/schemas/LayerConfig.json"},
+		humastar.Action{Rel: "collection", Href: fmt.Sprintf("/api/v1/tiles/%s/versions", b.name), Title: "Versions"},
+	)
+	return actions
+}
+
+type TileVersionsInput struct {
+	TileNameInput
+	ListInput
+}
+
+type TileVersionOutput struct {
+	Body TileVersionBody
+}
+
+// tileName adds the .pmtiles extension when a path omits it.
+func tileName(name string) string {
+	if !strings.HasSuffix(name, ".pmtiles") {
+		return name + ".pmtiles"
+	}
+	return name
+}
+
+// tileError maps a TileService error to its HTTP status: bad names and
+// archives are the client's fault, and anything unexpected is the server's.
+func tileError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidTileName), errors.Is(err, service.ErrInvalidArchive):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, service.ErrTileNotFound), errors.Is(err, service.ErrVersionNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, service.ErrTileExists), errors.Is(err, service.ErrTileInUse):
+		return huma.Error409Conflict(err.Error())
+	}
+	return huma.Error500InternalServerError(err.Error())
+}
+
+func (h *APIHandler) GetTile(ctx context.Context, input *TileNameInput) (*TileFileOutput, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error404NotFound("service not available")
+	}
+	file, err := h.svc.Tile.Get(tileName(input.Name))
+	if err != nil {
+		return nil, huma.Error404NotFound(err.Error())
+	}
+	return &TileFileOutput{Body: TileFileBody{file}}, nil
+}
+
+func (h *APIHandler) UploadTile(ctx context.Context, input *TileUploadInput) (*TileFileOutput, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	mediaType, params, err := mime.ParseMediaType(input.ContentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, huma.Error415UnsupportedMediaType("expected multipart/form-data")
+	}
+
+	name := ""
+	mr := multipart.NewReader(input.body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, huma.Error400BadRequest("No file provided")
+		}
+		if err != nil {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		switch part.FormName() {
+		case "name":
+			value, err := io.ReadAll(io.LimitReader(part, 256))
+			if err != nil {
+				return nil, huma.Error400BadRequest(err.Error())
+			}
+			name = tileName(strings.TrimSpace(string(value)))
+		case "file":
+			if name == "" {
+				name = tileName(part.FileName())
+			}
+			file, err := h.svc.Tile.Save(name, part)
+			if err != nil {
+				return nil, tileError(err)
+			}
+			return &TileFileOutput{Body: TileFileBody{file}}, nil
+		}
+	}
+}
+
+func (h *APIHandler) DeleteTile(ctx context.Context, input *TileDeleteInput) (*struct{ Body MessageBody }, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	if err := h.svc.Tile.Delete(tileName(input.Name), input.Force); err != nil {
+		if errors.Is(err, service.ErrTileInUse) {
+			return nil, huma.Error409Conflict(err.Error() + "; delete them or pass force=true")
+		}
+		return nil, tileError(err)
+	}
+	return &struct{ Body MessageBody }{Body: MessageBody{Message: "Tile file deleted"}}, nil
+}
+
+func (h *APIHandler) RenameTile(ctx context.Context, input *struct {
+	TileNameInput
+	Body RenameTileInput
+}) (*TileFileOutput, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	file, err := h.svc.Tile.Rename(tileName(input.Name), tileName(input.Body.Name))
+	if err != nil {
+		return nil, tileError(err)
+	}
+	return &TileFileOutput{Body: TileFileBody{file}}, nil
+}
+
+func (h *APIHandler) GetTileJSON(ctx context.Context, input *TileJSONInput) (*struct{ Body TileJSONBody }, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error404NotFound("service not available")
+	}
+	name := tileName(input.Name)
+	if _, err := h.svc.Tile.Get(name); err != nil {
+		return nil, huma.Error404NotFound(err.Error())
+	}
+	tj, err := h.svc.Tile.TileJSON(name, input.baseURL)
+	if err != nil {
+		return nil, huma.Error500InternalServerError(err.Error())
+	}
+	return &struct{ Body TileJSONBody }{Body: TileJSONBody{tj, name}}, nil
+}
+
+func (h *APIHandler) VerifyTile(ctx context.Context, input *TileNameInput) (*struct{ Body TileVerificationBody }, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	result, err := h.svc.Tile.Verify(tileName(input.Name))
+	if err != nil {
+		return nil, tileError(err)
+	}
+	return &struct{ Body TileVerificationBody }{Body: TileVerificationBody{result}}, nil
+}
+
+func (h *APIHandler) GetTileVersions(ctx context.Context, input *TileVersionsInput) (*struct {
+	Body humastar.PageBody[TileVersionBody]
+}, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error404NotFound("service not available")
+	}
+	name := tileName(input.Name)
+	versions, total, err := h.svc.Tile.VersionsPaged(name, input.Offset, input.Limit)
+	if err != nil {
+		return nil, tileError(err)
+	}
+	items := make([]TileVersionBody, len(versions))
+	for i, v := range versions {
+		items[i] = TileVersionBody{v, name}
+	}
+	return &struct{ Body humastar.PageBody[TileVersionBody] }{Body: humastar.PageBody[TileVersionBody]{
+		Total: total, Offset: input.Offset, Limit: input.Limit,
+		Data: items,
+	}}, nil
+}
+
+func (h *APIHandler) RollbackTileVersion(ctx context.Context, input *TileVersionInput) (*TileVersionOutput, error) {
+	if h.svc == nil || h.svc.Tile == nil {
+		return nil, huma.Error400BadRequest("service not available")
+	}
+	name := tileName(input.Name)
+	version, err := h.svc.Tile.Rollback(name, input.Version)
+	if err != nil {
+		return nil, tileError(err)
+	}
+	return &TileVersionOutput{Body: TileVersionBody{version, name}}, nil
+}
+
+// validatePin checks that a layer's pinned version exists.
+func (h *APIHandler) validatePin(layer service.LayerConfig) error {
+	if layer.Version == "" || h.svc.Tile == nil {
+		return nil
+	}
+	if _, ok := h.svc.Tile.Version(layer.File, layer.Version); !ok {
+		return huma.Error400BadRequest(fmt.Sprintf("version %q of %s not found", layer.Version, layer.File))
+	}
+	return nil
+}
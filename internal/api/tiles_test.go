@@ -0,0 +1,389 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/joeblew999/plat-geo/internal/humastar"
+	"github.com/joeblew999/plat-geo/internal/service"
+)
+
+// pointsGeoJSON is a small source the go engine tiles in milliseconds.
+const pointsGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"San Francisco","pop":800000},"geometry":{"type":"Point","coordinates":[-122.4,37.8]}},
+{"type":"Feature","properties":{"name":"Sydney","pop":5000000},"geometry":{"type":"Point","coordinates":[151.2,-33.9]}}]}`
+
+// newTestAPI registers the API routes over services for a temporary data
+// directory.
+func newTestAPI(t *testing.T) (humatest.TestAPI, *Services, string) {
+	t.Helper()
+	dataDir := t.TempDir()
+	layers := service.NewLayerService(dataDir)
+	tiles := service.NewTileService(dataDir, layers)
+	t.Cleanup(tiles.Close)
+	svc := &Services{
+		Layer:  layers,
+		Tile:   tiles,
+		Source: service.NewSourceService(dataDir),
+		Tiler:  service.NewTilerService(dataDir, tiles),
+	}
+
+	_, api := humatest.New(t)
+	huma.AutoRegister(api, NewAPIHandler(svc))
+	return api, svc, dataDir
+}
+
+// generatePoints tiles pointsGeoJSON into tileset name with the go engine.
+func generatePoints(t *testing.T, svc *Services, dataDir, name string) {
+	t.Helper()
+	sources := filepath.Join(dataDir, "sources")
+	if err := os.MkdirAll(sources, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sources, "points.geojson"), []byte(pointsGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := svc.Tiler.Generate(context.Background(), service.TileGenerateOptions{
+		SourceFile: "points.geojson",
+		OutputName: name,
+		LayerName:  "cities",
+		MinZoom:    1,
+		MaxZoom:    6,
+		Engine:     service.EngineGo,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetTileJSON(t *testing.T) {
+	api, svc, dataDir := newTestAPI(t)
+	generatePoints(t, svc, dataDir, "cities")
+
+	resp := api.Get("/api/v1/tiles/cities.pmtiles/tilejson.json", "Host: tiles.example.com", "X-Forwarded-Proto: https")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", resp.Code, resp.Body)
+	}
+	var tj service.TileJSON
+	if err := json.Unmarshal(resp.Body.Bytes(), &tj); err != nil {
+		t.Fatal(err)
+	}
+
+	if tj.TileJSON != "3.0.0" || tj.Scheme != "xyz" {
+		t.Errorf("tilejson %q, scheme %q", tj.TileJSON, tj.Scheme)
+	}
+	if len(tj.Tiles) != 1 || tj.Tiles[0] != "https://tiles.example.com/tiles/cities/{z}/{x}/{y}.mvt" {
+		t.Errorf("tiles = %q", tj.Tiles)
+	}
+	if tj.MinZoom != 1 || tj.MaxZoom != 6 {
+		t.Errorf("zooms = %d-%d, want 1-6", tj.MinZoom, tj.MaxZoom)
+	}
+
+	want := []float64{-122.4, -33.9, 151.2, 37.8}
+	if len(tj.Bounds) != 4 {
+		t.Fatalf("bounds = %v", tj.Bounds)
+	}
+	for i := range want {
+		if math.Abs(tj.Bounds[i]-want[i]) > 1e-6 {
+			t.Errorf("bounds = %v, want %v", tj.Bounds, want)
+			break
+		}
+	}
+
+	if len(tj.VectorLayers) != 1 {
+		t.Fatalf("vector_layers = %+v, want one layer", tj.VectorLayers)
+	}
+	layer := tj.VectorLayers[0]
+	if layer.ID != "cities" || layer.MinZoom != 1 || layer.MaxZoom != 6 {
+		t.Errorf("vector layer = %+v, want cities at zooms 1-6", layer)
+	}
+	if len(layer.Fields) != 2 || layer.Fields["name"] != "String" || layer.Fields["pop"] != "Number" {
+		t.Errorf("vector layer fields = %v", layer.Fields)
+	}
+
+	if resp := api.Get("/api/v1/tiles/missing.pmtiles/tilejson.json"); resp.Code != http.StatusNotFound {
+		t.Errorf("missing tileset: status %d, want 404", resp.Code)
+	}
+}
+
+// multipartBody encodes form fields, and a file field when file is not
+// nil, returning the body and its Content-Type header argument.
+func multipartBody(t *testing.T, fields map[string]string, filename string, file []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	for k, v := range fields {
+		w.WriteField(k, v)
+	}
+	if file != nil {
+		fw, err := w.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(file)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &b, "Content-Type: " + w.FormDataContentType()
+}
+
+func TestUploadTile(t *testing.T) {
+	api, svc, dataDir := newTestAPI(t)
+	generatePoints(t, svc, dataDir, "cities")
+	archive, err := os.ReadFile(filepath.Join(dataDir, "tiles", "cities.pmtiles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		filename string
+		file     []byte
+		want     int
+	}{
+		{"named by field", map[string]string{"name": "renamed"}, "cities.pmtiles", archive, http.StatusCreated},
+		{"named by file", nil, "uploaded.pmtiles", archive, http.StatusCreated},
+		{"invalid archive", nil, "garbage.pmtiles", []byte("not an archive"), http.StatusBadRequest},
+		{"hidden name", map[string]string{"name": ".hidden"}, "cities.pmtiles", archive, http.StatusBadRequest},
+		{"traversal name", map[string]string{"name": "../escape"}, "cities.pmtiles", archive, http.StatusBadRequest},
+		{"no file", map[string]string{"name": "empty"}, "", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		body, contentType := multipartBody(t, tt.fields, tt.filename, tt.file)
+		resp := api.Post("/api/v1/tiles", contentType, body)
+		if resp.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, resp.Code, tt.want, resp.Body)
+		}
+	}
+
+	for _, name := range []string{"renamed.pmtiles", "uploaded.pmtiles"} {
+		if _, err := svc.Tile.Get(name); err != nil {
+			t.Errorf("uploaded %s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"garbage.pmtiles", "escape.pmtiles"} {
+		if _, err := os.Stat(filepath.Join(dataDir, "tiles", name)); !os.IsNotExist(err) {
+			t.Errorf("rejected upload %s was stored: %v", name, err)
+		}
+	}
+
+	if resp := api.Post("/api/v1/tiles", "Content-Type: application/octet-stream", bytes.NewReader(archive)); resp.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("non-multipart upload: status %d, want 415", resp.Code)
+	}
+}
+
+func TestRenameTile(t *testing.T) {
+	api, svc, dataDir := newTestAPI(t)
+	generatePoints(t, svc, dataDir, "cities")
+	generatePoints(t, svc, dataDir, "taken")
+	if _, err := svc.Layer.Create(service.LayerConfig{ID: "cities", Name: "Cities", File: "cities.pmtiles", GeomType: "point"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc, from, to string
+		want           int
+	}{
+		{"invalid target", "cities", "sub/towns", http.StatusBadRequest},
+		{"hidden target", "cities", ".towns", http.StatusBadRequest},
+		{"missing source", "missing", "towns", http.StatusNotFound},
+		{"existing target", "cities", "taken.pmtiles", http.StatusConflict},
+		{"renamed", "cities", "towns", http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := api.Post("/api/v1/tiles/"+tt.from+"/rename", map[string]any{"name": tt.to})
+		if resp.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.desc, resp.Code, tt.want, resp.Body)
+		}
+	}
+
+	if _, err := svc.Tile.Get("towns.pmtiles"); err != nil {
+		t.Errorf("renamed tileset: %v", err)
+	}
+	if _, err := svc.Tile.Get("cities.pmtiles"); err == nil {
+		t.Error("old name still exists after rename")
+	}
+	if layer, _ := svc.Layer.Get("cities"); layer.File != "towns.pmtiles" {
+		t.Errorf("layer file = %q after rename, want towns.pmtiles", layer.File)
+	}
+	if _, err := svc.Tile.Get("taken.pmtiles"); err != nil {
+		t.Errorf("conflicting rename touched the target: %v", err)
+	}
+}
+
+func TestDeleteTile(t *testing.T) {
+	api, svc, dataDir := newTestAPI(t)
+	generatePoints(t, svc, dataDir, "cities")
+	if _, err := svc.Layer.Create(service.LayerConfig{ID: "cities", Name: "Cities", File: "cities.pmtiles", GeomType: "point"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := api.Delete("/api/v1/tiles/cities.pmtiles")
+	if resp.Code != http.StatusConflict {
+		t.Errorf("deleting a tileset in use: status %d, want 409", resp.Code)
+	}
+	if !strings.Contains(resp.Body.String(), "cities") || !strings.Contains(resp.Body.String(), "force=true") {
+		t.Errorf("in use error does not name the layers and force: %s", resp.Body)
+	}
+	if _, err := svc.Tile.Get("cities.pmtiles"); err != nil {
+		t.Fatalf("tileset in use was deleted: %v", err)
+	}
+
+	if resp := api.Delete("/api/v1/tiles/cities?force=true"); resp.Code != http.StatusOK {
+		t.Errorf("forced delete: status %d, want 200: %s", resp.Code, resp.Body)
+	}
+	if _, err := svc.Tile.Get("cities.pmtiles"); err == nil {
+		t.Error("tileset still exists after forced delete")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "tiles", "versions", "cities")); !os.IsNotExist(err) {
+		t.Errorf("versions still exist after delete: %v", err)
+	}
+
+	if resp := api.Delete("/api/v1/tiles/cities"); resp.Code != http.StatusNotFound {
+		t.Errorf("deleting a missing tileset: status %d, want 404", resp.Code)
+	}
+	if resp := api.Delete("/api/v1/tiles/.cities"); resp.Code != http.StatusBadRequest {
+		t.Errorf("deleting an invalid name: status %d, want 400", resp.Code)
+	}
+}
+
+func TestTileVersions(t *testing.T) {
+	api, svc, dataDir := newTestAPI(t)
+	generatePoints(t, svc, dataDir, "cities")
+	err := svc.Tiler.Generate(context.Background(), service.TileGenerateOptions{
+		SourceFile: "points.geojson",
+		OutputName: "cities",
+		LayerName:  "cities",
+		MaxZoom:    3,
+		Engine:     service.EngineGo,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var page humastar.PageBody[service.TileVersion]
+	resp := api.Get("/api/v1/tiles/cities/versions?limit=1&offset=1")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", resp.Code, resp.Body)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || page.Offset != 1 || page.Limit != 1 || len(page.Data) != 1 {
+		t.Fatalf("page = %+v, want the second of two versions", page)
+	}
+	older := page.Data[0]
+	if older.Current {
+		t.Errorf("older version %s is current", older.ID)
+	}
+
+	// The older version can be rolled back to and pinned; the current
+	// one only pinned
+	rels := func(v service.TileVersion) map[string]humastar.Action {
+		m := map[string]humastar.Action{}
+		for _, a := range (TileVersionBody{v, "cities.pmtiles"}).Actions() {
+			m[a.Rel] = a
+		}
+		return m
+	}
+	actions := rels(older)
+	if a := actions["rollback"]; a.Method != http.MethodPost || a.Href != "/api/v1/tiles/cities.pmtiles/versions/"+older.ID+"/rollback" {
+		t.Errorf("rollback action = %+v", a)
+	}
+	if a := actions["pin"]; a.Method != http.MethodPost || a.Href != "/api/v1/layers" {
+		t.Errorf("pin action = %+v", a)
+	}
+	if _, ok := rels(service.TileVersion{ID: older.ID, Current: true})["rollback"]; ok {
+		t.Error("the current version offers a rollback")
+	}
+
+	resp = api.Post("/api/v1/tiles/cities/versions/" + older.ID + "/rollback")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("rollback: status %d: %s", resp.Code, resp.Body)
+	}
+	if v, _ := svc.Tile.Version("cities.pmtiles", older.ID); !v.Current {
+		t.Errorf("version %s is not current after rollback", older.ID)
+	}
+
+	tests := []struct {
+		desc, method, path string
+		want               int
+	}{
+		{"versions of a missing tileset", http.MethodGet, "/api/v1/tiles/missing/versions", http.StatusNotFound},
+		{"versions of an invalid name", http.MethodGet, "/api/v1/tiles/.cities/versions", http.StatusBadRequest},
+		{"rollback to a missing version", http.MethodPost, "/api/v1/tiles/cities/versions/000000000000/rollback", http.StatusNotFound},
+		{"rollback of a missing tileset", http.MethodPost, "/api/v1/tiles/missing/versions/" + older.ID + "/rollback", http.StatusNotFound},
+		{"rollback of an invalid name", http.MethodPost, "/api/v1/tiles/.cities/versions/" + older.ID + "/rollback", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if resp := api.Do(tt.method, tt.path); resp.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.desc, resp.Code, tt.want, resp.Body)
+		}
+	}
+}
+
+func TestVerifyTile(t *testing.T) {
+	api, svc, dataDir := newTestAPI(t)
+	generatePoints(t, svc, dataDir, "cities")
+	archive, err := os.ReadFile(filepath.Join(dataDir, "tiles", "cities.pmtiles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bytes 72-79 of the header hold the addressed tile count
+	archive[72]++
+	if err := os.WriteFile(filepath.Join(dataDir, "tiles", "miscounted.pmtiles"), archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "tiles", "garbage.pmtiles"), []byte("not an archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		wantValid bool
+		want      string // in the first problem
+	}{
+		{"cities", true, ""},
+		{"miscounted.pmtiles", false, "header addresses"},
+		{"garbage", false, "header"},
+	}
+	for _, tt := range tests {
+		resp := api.Post("/api/v1/tiles/" + tt.name + "/verify")
+		if resp.Code != http.StatusOK {
+			t.Errorf("%s: status %d: %s", tt.name, resp.Code, resp.Body)
+			continue
+		}
+		var v service.TileVerification
+		if err := json.Unmarshal(resp.Body.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Valid != tt.wantValid || v.Valid != (len(v.Problems) == 0) {
+			t.Errorf("%s: valid %v with problems %q, want valid %v", tt.name, v.Valid, v.Problems, tt.wantValid)
+		}
+		if tt.want != "" && (len(v.Problems) == 0 || !strings.Contains(v.Problems[0], tt.want)) {
+			t.Errorf("%s: problems %q, want one about %s", tt.name, v.Problems, tt.want)
+		}
+		if tt.wantValid && (v.Entries == 0 || v.Tiles == 0) {
+			t.Errorf("%s: checked %d entries and %d tiles", tt.name, v.Entries, v.Tiles)
+		}
+	}
+
+	if resp := api.Post("/api/v1/tiles/missing/verify"); resp.Code != http.StatusNotFound {
+		t.Errorf("missing tileset: status %d, want 404", resp.Code)
+	}
+	if resp := api.Post("/api/v1/tiles/.cities/verify"); resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid name: status %d, want 400", resp.Code)
+	}
+}
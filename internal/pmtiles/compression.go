@@ -0,0 +1,163 @@
+package pmtiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// String returns the name used for the compression in TileJSON metadata.
+func (c Compression) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case Gzip:
+		return "gzip"
+	case Brotli:
+		return "br"
+	case Zstd:
+		return "zstd"
+	}
+	return "unknown"
+}
+
+// ParseCompression converts a compression name to a Compression.
+// Both the TileJSON names ("br") and spelled-out names ("brotli") are accepted.
+func ParseCompression(s string) (Compression, error) {
+	switch strings.ToLower(s) {
+	case "none", "identity":
+		return NoCompression, nil
+	case "", "gzip":
+		return Gzip, nil
+	case "br", "brotli":
+		return Brotli, nil
+	case "zstd":
+		return Zstd, nil
+	}
+	return UnknownCompression, fmt.Errorf("unknown compression %q", s)
+}
+
+// String returns the name used for the tile type in TileJSON metadata.
+func (t TileType) String() string {
+	switch t {
+	case Mvt:
+		return "mvt"
+	case Png:
+		return "png"
+	case Jpeg:
+		return "jpg"
+	case Webp:
+		return "webp"
+	case Avif:
+		return "avif"
+	}
+	return "unknown"
+}
+
+// MediaType returns the HTTP Content-Type of tiles of this type.
+func (t TileType) MediaType() string {
+	switch t {
+	case Mvt:
+		return "application/vnd.mapbox-vector-tile"
+	case Png:
+		return "image/png"
+	case Jpeg:
+		return "image/jpeg"
+	case Webp:
+		return "image/webp"
+	case Avif:
+		return "image/avif"
+	}
+	return "application/octet-stream"
+}
+
+// nopWriteCloser adapts a buffer for uncompressed output.
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (w *nopWriteCloser) Close() error { return nil }
+
+// newCompressWriter wraps b with an encoder at the highest compression
+// level, since archives are written once and read many times.
+func newCompressWriter(b *bytes.Buffer, compression Compression) (io.WriteCloser, error) {
+	switch compression {
+	case NoCompression:
+		return &nopWriteCloser{b}, nil
+	case Gzip:
+		return gzip.NewWriterLevel(b, gzip.BestCompression)
+	case Brotli:
+		return brotli.NewWriterLevel(b, brotli.BestCompression), nil
+	case Zstd:
+		return zstd.NewWriter(b, zstd.WithEncoderLevel(zstd.SpeedBestCompression), zstd.WithEncoderConcurrency(1))
+	}
+	return nil, fmt.Errorf("compression %d not supported", compression)
+}
+
+// Compress applies compression to a directory, the metadata, or a tile.
+func Compress(data []byte, compression Compression) ([]byte, error) {
+	if compression == NoCompression {
+		return data, nil
+	}
+	var b bytes.Buffer
+	w, err := newCompressWriter(&b, compression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// maxDecompressedSize bounds what Decompress inflates a section to, far
+// above any real directory, metadata or tile, so a crafted archive can't
+// exhaust memory with a few compressed bytes.
+const maxDecompressedSize = 64 << 20
+
+// Decompress reverses the compression applied to a directory, the metadata,
+// or an individual tile.
+func Decompress(data []byte, compression Compression) ([]byte, error) {
+	switch compression {
+	case NoCompression, UnknownCompression:
+		return data, nil
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readLimited(r)
+	case Brotli:
+		return readLimited(brotli.NewReader(bytes.NewReader(data)))
+	case Zstd:
+		r, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readLimited(r)
+	}
+	return nil, fmt.Errorf("compression %d not supported", compression)
+}
+
+// readLimited reads r to the end, failing once it passes
+// maxDecompressedSize bytes.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed data exceeds %d bytes", maxDecompressedSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,48 @@
+package pmtiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDecompressLimit(t *testing.T) {
+	compress := map[Compression]func(w io.Writer) io.WriteCloser{
+		Gzip:   func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		Brotli: func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, 1) },
+		Zstd: func(w io.Writer) io.WriteCloser {
+			z, _ := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+			return z
+		},
+	}
+	zeros := make([]byte, 1<<20)
+	for c, newWriter := range compress {
+		// A bomb of zeros one byte past the limit
+		var bomb bytes.Buffer
+		w := newWriter(&bomb)
+		for range maxDecompressedSize / len(zeros) {
+			w.Write(zeros)
+		}
+		w.Write([]byte{0})
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Decompress(bomb.Bytes(), c); err == nil || !strings.Contains(err.Error(), "exceeds") {
+			t.Errorf("%s: %d compressed bytes past the limit: err = %v", c, bomb.Len(), err)
+		}
+
+		// Data within the limit round-trips
+		data, err := Compress([]byte("tile"), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := Decompress(data, c); err != nil || string(got) != "tile" {
+			t.Errorf("%s: round trip = %q, %v", c, got, err)
+		}
+	}
+}
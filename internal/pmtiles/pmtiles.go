@@ -1,9 +1,9 @@
 // Package pmtiles provides PMTiles v3 format support for tile generation.
 //
 // This is a minimal subset of github.com/protomaps/go-pmtiles/pmtiles,
-// containing only the functions needed for PMTiles writing. It excludes
-// the MBTiles conversion code which depends on SQLite, making this
-// package WASM-compatible for Cloudflare Workers deployment.
+// containing only the functions needed to write and read PMTiles. It
+// excludes the MBTiles conversion code which depends on SQLite, making
+// this package WASM-compatible for Cloudflare Workers deployment.
 //
 // Source: https://github.com/protomaps/go-pmtiles (BSD-3-Clause)
 // Spec: https://github.com/protomaps/PMTiles/blob/main/spec/v3/spec.md
@@ -11,11 +11,9 @@ package pmtiles
 
 import (
 	"bytes"
-	"compress/gzip"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
-	"io"
 )
 
 // Compression is the compression algorithm applied to individual tiles.
@@ -44,6 +42,10 @@ const (
 // HeaderV3LenBytes is the fixed-size binary header.
 const HeaderV3LenBytes = 127
 
+// RootDirectoryMaxLen is the space left for the root directory when header
+// and root must fit in the first 16 KiB fetched by clients.
+const RootDirectoryMaxLen = 16384 - HeaderV3LenBytes
+
 // HeaderV3 is a binary header for PMTiles v3.
 type HeaderV3 struct {
 	SpecVersion         uint8
@@ -73,6 +75,11 @@ type HeaderV3 struct {
 	CenterLatE7         int32
 }
 
+// FromE7 converts a header coordinate stored in units of 1e-7 degrees.
+func FromE7(v int32) float64 {
+	return float64(v) / 1e7
+}
+
 // EntryV3 is an entry in a PMTiles v3 directory.
 type EntryV3 struct {
 	TileID    uint64
@@ -95,6 +102,35 @@ func ZxyToID(z uint8, x uint32, y uint32) uint64 {
 	return acc
 }
 
+// IDToZxy converts a Hilbert TileID back to (Z,X,Y) tile coordinates.
+func IDToZxy(i uint64) (uint8, uint32, uint32) {
+	var acc uint64
+	for z := uint8(0); z < 32; z++ {
+		numTiles := uint64(1) << (z * 2)
+		if acc+numTiles > i {
+			x, y := idOnLevel(z, i-acc)
+			return z, x, y
+		}
+		acc += numTiles
+	}
+	return 32, 0, 0
+}
+
+func idOnLevel(z uint8, pos uint64) (uint32, uint32) {
+	n := uint64(1) << z
+	t := pos
+	var x, y uint32
+	for s := uint64(1); s < n; s *= 2 {
+		rx := 1 & (t / 2)
+		ry := 1 & (t ^ rx)
+		x, y = rotate(uint32(s), x, y, uint32(rx), uint32(ry))
+		x += uint32(s * rx)
+		y += uint32(s * ry)
+		t /= 4
+	}
+	return x, y
+}
+
 func rotate(n uint32, x uint32, y uint32, rx uint32, ry uint32) (uint32, uint32) {
 	if ry == 0 {
 		if rx != 0 {
@@ -147,28 +183,9 @@ func SerializeMetadata(metadata map[string]interface{}, compression Compression)
 	if err != nil {
 		return nil, err
 	}
-
-	if compression == NoCompression {
-		return jsonBytes, nil
-	} else if compression == Gzip {
-		var b bytes.Buffer
-		w, err := gzip.NewWriterLevel(&b, gzip.BestCompression)
-		if err != nil {
-			return nil, err
-		}
-		w.Write(jsonBytes)
-		w.Close()
-		return b.Bytes(), nil
-	}
-	return nil, errors.New("compression not supported")
-}
-
-type nopWriteCloser struct {
-	*bytes.Buffer
+	return Compress(jsonBytes, compression)
 }
 
-func (w *nopWriteCloser) Close() error { return nil }
-
 // DeserializeHeader parses a binary header.
 func DeserializeHeader(d []byte) (HeaderV3, error) {
 	h := HeaderV3{}
@@ -211,15 +228,11 @@ func DeserializeHeader(d []byte) (HeaderV3, error) {
 // SerializeEntries converts directory entries to compressed bytes.
 func SerializeEntries(entries []EntryV3, compression Compression) []byte {
 	var b bytes.Buffer
-	var w io.WriteCloser
 
 	tmp := make([]byte, binary.MaxVarintLen64)
-	if compression == NoCompression {
-		w = &nopWriteCloser{&b}
-	} else if compression == Gzip {
-		w, _ = gzip.NewWriterLevel(&b, gzip.BestCompression)
-	} else {
-		panic("Compression not supported")
+	w, err := newCompressWriter(&b, compression)
+	if err != nil {
+		panic(err) // only reachable with an undeclared Compression value
 	}
 
 	var n int
@@ -256,3 +269,55 @@ func SerializeEntries(entries []EntryV3, compression Compression) []byte {
 	w.Close()
 	return b.Bytes()
 }
+
+// OptimizeDirectories serializes sorted tile entries into a root directory
+// no larger than targetRootLen, splitting them into leaf directories when
+// they don't fit. Leaf pointers in the root use offsets relative to the
+// start of the returned leaves section.
+func OptimizeDirectories(entries []EntryV3, targetRootLen int, compression Compression) (root []byte, leaves []byte, numLeaves int) {
+	if len(entries) < 16384 {
+		root = SerializeEntries(entries, compression)
+		if len(root) <= targetRootLen {
+			return root, nil, 0
+		}
+	}
+
+	// Root holds leaf pointers only. Grow the leaf size until the root fits.
+	leafSize := float32(len(entries)) / 3500
+	if leafSize < 4096 {
+		leafSize = 4096
+	}
+	for {
+		root, leaves, numLeaves = buildRootsLeaves(entries, int(leafSize), compression)
+		if len(root) <= targetRootLen {
+			return root, leaves, numLeaves
+		}
+		leafSize *= 1.2
+	}
+}
+
+// buildRootsLeaves splits entries into leaf directories of leafSize entries.
+func buildRootsLeaves(entries []EntryV3, leafSize int, compression Compression) ([]byte, []byte, int) {
+	var rootEntries []EntryV3
+	var leaves []byte
+	numLeaves := 0
+
+	for idx := 0; idx < len(entries); idx += leafSize {
+		end := idx + leafSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		serialized := SerializeEntries(entries[idx:end], compression)
+
+		rootEntries = append(rootEntries, EntryV3{
+			TileID:    entries[idx].TileID,
+			Offset:    uint64(len(leaves)),
+			Length:    uint32(len(serialized)),
+			RunLength: 0,
+		})
+		leaves = append(leaves, serialized...)
+		numLeaves++
+	}
+
+	return SerializeEntries(rootEntries, compression), leaves, numLeaves
+}
@@ -0,0 +1,298 @@
+package pmtiles
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// maxDirectoryDepth bounds root → leaf traversal. The spec allows leaf
+// directories to nest, but writers never go deeper than this.
+const maxDirectoryDepth = 4
+
+// maxCachedLeaves bounds the decoded leaf directories a Reader keeps.
+// Past it an arbitrary one is dropped; map requests cluster, so the
+// leaves in use are soon decoded again.
+const maxCachedLeaves = 64
+
+// DeserializeEntries decodes a compressed directory into entries.
+func DeserializeEntries(data []byte, compression Compression) ([]EntryV3, error) {
+	raw, err := Decompress(data, compression)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing directory: %w", err)
+	}
+	r := bufio.NewReader(bytes.NewReader(raw))
+
+	numEntries, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading entry count: %w", err)
+	}
+	// Every entry takes at least four bytes, which catches corrupt counts
+	// before they turn into huge allocations.
+	if numEntries > uint64(len(raw))/4 {
+		return nil, errors.New("directory entry count exceeds directory size")
+	}
+
+	entries := make([]EntryV3, numEntries)
+	lastID := uint64(0)
+	for i := range entries {
+		delta, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading tile IDs: %w", err)
+		}
+		lastID += delta
+		entries[i].TileID = lastID
+	}
+	for i := range entries {
+		runLength, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading run lengths: %w", err)
+		}
+		entries[i].RunLength = uint32(runLength)
+	}
+	for i := range entries {
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading lengths: %w", err)
+		}
+		entries[i].Length = uint32(length)
+	}
+	for i := range entries {
+		offset, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading offsets: %w", err)
+		}
+		switch {
+		case offset > 0:
+			entries[i].Offset = offset - 1
+		case i > 0:
+			// Zero means the entry's data follows the previous entry's
+			entries[i].Offset = entries[i-1].Offset + uint64(entries[i-1].Length)
+		default:
+			return nil, errors.New("corrupt directory: first entry has no offset")
+		}
+	}
+	return entries, nil
+}
+
+// FindTile searches a sorted directory for the entry covering tileID.
+// The returned entry is either a tile (RunLength > 0) whose run includes
+// tileID, or a leaf directory pointer (RunLength == 0) to descend into.
+func FindTile(entries []EntryV3, tileID uint64) (EntryV3, bool) {
+	m := 0
+	n := len(entries) - 1
+	for m <= n {
+		k := (n + m) >> 1
+		switch {
+		case tileID > entries[k].TileID:
+			m = k + 1
+		case tileID < entries[k].TileID:
+			n = k - 1
+		default:
+			return entries[k], true
+		}
+	}
+
+	// m > n: entries[n] is the closest entry with a smaller tile ID.
+	if n >= 0 {
+		if entries[n].RunLength == 0 {
+			return entries[n], true
+		}
+		if tileID-entries[n].TileID < uint64(entries[n].RunLength) {
+			return entries[n], true
+		}
+	}
+	return EntryV3{}, false
+}
+
+// Reader provides random access to tiles in a PMTiles v3 archive.
+// It is safe for concurrent use.
+type Reader struct {
+	r      io.ReaderAt
+	size   uint64
+	closer io.Closer
+	header HeaderV3
+	root   []EntryV3
+
+	mu     sync.Mutex
+	leaves map[uint64][]EntryV3 // decoded leaf directories keyed by offset, at most maxCachedLeaves
+}
+
+// Open opens a PMTiles archive on disk.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewReader reads the header and root directory from r, an archive of
+// size bytes. Sections the header or directories place past the end are
+// rejected before anything is allocated for them.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	buf := make([]byte, HeaderV3LenBytes)
+	if _, err := r.ReadAt(buf, 0); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	header, err := DeserializeHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if header.SpecVersion != 3 {
+		return nil, fmt.Errorf("unsupported spec version %d", header.SpecVersion)
+	}
+
+	rd := &Reader{
+		r:      r,
+		size:   uint64(max(size, 0)),
+		header: header,
+		leaves: make(map[uint64][]EntryV3),
+	}
+	rd.root, err = rd.readDirectory(header.RootOffset, header.RootLength)
+	if err != nil {
+		return nil, fmt.Errorf("reading root directory: %w", err)
+	}
+	return rd, nil
+}
+
+// Header returns the archive header.
+func (rd *Reader) Header() HeaderV3 {
+	return rd.header
+}
+
+// Metadata returns the decoded JSON metadata.
+func (rd *Reader) Metadata() (map[string]any, error) {
+	data, err := rd.readSection(rd.header.MetadataOffset, rd.header.MetadataLength)
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata: %w", err)
+	}
+	raw, err := Decompress(data, rd.header.InternalCompression)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing metadata: %w", err)
+	}
+	metadata := make(map[string]any)
+	if len(raw) == 0 {
+		return metadata, nil
+	}
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, fmt.Errorf("parsing metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// Tile returns the stored bytes of tile z/x/y, still compressed with the
+// header's TileCompression. The bool is false when the archive has no
+// tile at that address.
+func (rd *Reader) Tile(z uint8, x, y uint32) ([]byte, bool, error) {
+	if z < rd.header.MinZoom || z > rd.header.MaxZoom {
+		return nil, false, nil
+	}
+	if x >= 1<<z || y >= 1<<z {
+		return nil, false, nil
+	}
+	return rd.TileByID(ZxyToID(z, x, y))
+}
+
+// TileByID returns the stored bytes of the tile with the given Hilbert ID.
+func (rd *Reader) TileByID(tileID uint64) ([]byte, bool, error) {
+	entries := rd.root
+	for depth := 0; depth < maxDirectoryDepth; depth++ {
+		entry, ok := FindTile(entries, tileID)
+		if !ok {
+			return nil, false, nil
+		}
+		if entry.RunLength > 0 {
+			data, err := rd.readSection(rd.header.TileDataOffset+entry.Offset, uint64(entry.Length))
+			if err != nil {
+				return nil, false, fmt.Errorf("reading tile %d: %w", tileID, err)
+			}
+			return data, true, nil
+		}
+		leaf, err := rd.leaf(entry.Offset, uint64(entry.Length))
+		if err != nil {
+			return nil, false, err
+		}
+		entries = leaf
+	}
+	return nil, false, errors.New("leaf directories nested too deeply")
+}
+
+// Close releases the underlying file when the reader was created with Open.
+func (rd *Reader) Close() error {
+	if rd.closer != nil {
+		return rd.closer.Close()
+	}
+	return nil
+}
+
+// leaf returns a decoded leaf directory, caching it for later lookups.
+func (rd *Reader) leaf(offset, length uint64) ([]EntryV3, error) {
+	rd.mu.Lock()
+	entries, ok := rd.leaves[offset]
+	rd.mu.Unlock()
+	if ok {
+		return entries, nil
+	}
+
+	entries, err := rd.readDirectory(rd.header.LeafDirectoryOffset+offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("reading leaf directory at %d: %w", offset, err)
+	}
+
+	rd.mu.Lock()
+	if len(rd.leaves) >= maxCachedLeaves {
+		for cached := range rd.leaves {
+			delete(rd.leaves, cached)
+			break
+		}
+	}
+	rd.leaves[offset] = entries
+	rd.mu.Unlock()
+	return entries, nil
+}
+
+// readDirectory reads and decodes a directory at an absolute offset.
+func (rd *Reader) readDirectory(offset, length uint64) ([]EntryV3, error) {
+	data, err := rd.readSection(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return DeserializeEntries(data, rd.header.InternalCompression)
+}
+
+// readSection reads length bytes at an absolute offset.
+func (rd *Reader) readSection(offset, length uint64) ([]byte, error) {
+	if offset > rd.size || length > rd.size-offset {
+		return nil, fmt.Errorf("section at offset %d (length %d) extends past the end of the archive", offset, length)
+	}
+	buf := make([]byte, length)
+	if length == 0 {
+		return buf, nil
+	}
+	n, err := rd.r.ReadAt(buf, int64(offset))
+	if n == len(buf) {
+		return buf, nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
@@ -0,0 +1,277 @@
+package pmtiles
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// buildArchive assembles an archive with one leaf directory in memory.
+func buildArchive(t *testing.T, leafEntries []EntryV3, tileData []byte) []byte {
+	t.Helper()
+	return buildLeavesArchive(t, [][]EntryV3{leafEntries}, tileData)
+}
+
+// buildLeavesArchive assembles an archive in memory whose root directory
+// points at each of leaves in turn.
+func buildLeavesArchive(t *testing.T, leaves [][]EntryV3, tileData []byte) []byte {
+	t.Helper()
+
+	var leafBytes []byte
+	var root []EntryV3
+	for _, entries := range leaves {
+		leaf := SerializeEntries(entries, Gzip)
+		root = append(root, EntryV3{TileID: entries[0].TileID, Offset: uint64(len(leafBytes)), Length: uint32(len(leaf)), RunLength: 0})
+		leafBytes = append(leafBytes, leaf...)
+	}
+	rootBytes := SerializeEntries(root, Gzip)
+	metadataBytes, err := SerializeMetadata(map[string]any{"name": "test"}, Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := HeaderV3{
+		SpecVersion:         3,
+		RootOffset:          HeaderV3LenBytes,
+		RootLength:          uint64(len(rootBytes)),
+		InternalCompression: Gzip,
+		TileCompression:     NoCompression,
+		TileType:            Mvt,
+		MaxZoom:             2,
+	}
+	header.MetadataOffset = header.RootOffset + header.RootLength
+	header.MetadataLength = uint64(len(metadataBytes))
+	header.LeafDirectoryOffset = header.MetadataOffset + header.MetadataLength
+	header.LeafDirectoryLength = uint64(len(leafBytes))
+	header.TileDataOffset = header.LeafDirectoryOffset + header.LeafDirectoryLength
+	header.TileDataLength = uint64(len(tileData))
+
+	var b bytes.Buffer
+	b.Write(SerializeHeader(header))
+	b.Write(rootBytes)
+	b.Write(metadataBytes)
+	b.Write(leafBytes)
+	b.Write(tileData)
+	return b.Bytes()
+}
+
+func TestReaderLeafAndRunLength(t *testing.T) {
+	tileData := []byte("aaabb")
+	archive := buildArchive(t, []EntryV3{
+		{TileID: ZxyToID(1, 0, 0), Offset: 0, Length: 3, RunLength: 2},
+		{TileID: ZxyToID(2, 0, 0), Offset: 3, Length: 2, RunLength: 1},
+	}, tileData)
+
+	r, err := NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		z    uint8
+		x, y uint32
+		want string
+		ok   bool
+	}{
+		{1, 0, 0, "aaa", true},
+		{1, 0, 1, "aaa", true}, // second tile of the run
+		{2, 0, 0, "bb", true},
+		{0, 0, 0, "", false},
+		{1, 1, 1, "", false},
+		{3, 0, 0, "", false}, // above MaxZoom
+	}
+	for _, tt := range tests {
+		data, ok, err := r.Tile(tt.z, tt.x, tt.y)
+		if err != nil {
+			t.Fatalf("%d/%d/%d: %v", tt.z, tt.x, tt.y, err)
+		}
+		if ok != tt.ok || string(data) != tt.want {
+			t.Errorf("%d/%d/%d = %q, %v; want %q, %v", tt.z, tt.x, tt.y, data, ok, tt.want, tt.ok)
+		}
+	}
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata["name"] != "test" {
+		t.Errorf("metadata name = %v, want test", metadata["name"])
+	}
+}
+
+func TestReaderCachesBoundedLeaves(t *testing.T) {
+	// Every leaf holds one tile, all sharing the same byte of tile data
+	leaves := make([][]EntryV3, 2*maxCachedLeaves)
+	for i := range leaves {
+		leaves[i] = []EntryV3{{TileID: uint64(i), Offset: 0, Length: 1, RunLength: 1}}
+	}
+	archive := buildLeavesArchive(t, leaves, []byte("x"))
+	r, err := NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for pass := 0; pass < 2; pass++ {
+		for id := range uint64(len(leaves)) {
+			data, ok, err := r.TileByID(id)
+			if err != nil || !ok || string(data) != "x" {
+				t.Fatalf("tile %d = %q, %v, %v", id, data, ok, err)
+			}
+			if n := len(r.leaves); n > maxCachedLeaves {
+				t.Fatalf("%d leaf directories cached, want at most %d", n, maxCachedLeaves)
+			}
+		}
+	}
+}
+
+func TestDeserializeEntriesRoundTrip(t *testing.T) {
+	entries := []EntryV3{
+		{TileID: 0, Offset: 0, Length: 10, RunLength: 1},
+		{TileID: 1, Offset: 10, Length: 5, RunLength: 3},
+		{TileID: 9, Offset: 0, Length: 10, RunLength: 1}, // deduplicated content
+	}
+	for _, c := range []Compression{NoCompression, Gzip, Brotli, Zstd} {
+		got, err := DeserializeEntries(SerializeEntries(entries, c), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(entries) {
+			t.Fatalf("got %d entries, want %d", len(got), len(entries))
+		}
+		for i := range entries {
+			if got[i] != entries[i] {
+				t.Errorf("compression %d entry %d = %+v, want %+v", c, i, got[i], entries[i])
+			}
+		}
+	}
+}
+
+func TestIDToZxyRoundTrip(t *testing.T) {
+	for z := uint8(0); z <= 6; z++ {
+		for x := uint32(0); x < 1<<z; x++ {
+			for y := uint32(0); y < 1<<z; y++ {
+				gz, gx, gy := IDToZxy(ZxyToID(z, x, y))
+				if gz != z || gx != x || gy != y {
+					t.Fatalf("%d/%d/%d round-tripped to %d/%d/%d", z, x, y, gz, gx, gy)
+				}
+			}
+		}
+	}
+}
+
+func TestZoomStatsSplitsRuns(t *testing.T) {
+	archive := buildArchive(t, []EntryV3{
+		{TileID: ZxyToID(1, 0, 0), Offset: 0, Length: 3, RunLength: 2},
+		{TileID: ZxyToID(2, 0, 0) - 1, Offset: 3, Length: 2000, RunLength: 2}, // last z1 tile and first z2 tile
+	}, make([]byte, 2003))
+
+	r, err := NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := r.ZoomStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d zooms, want 2", len(stats))
+	}
+	if z1 := stats[0]; z1.Zoom != 1 || z1.Tiles != 3 || z1.Bytes != 2006 || z1.MaxBytes != 2000 || z1.Sizes[0] != 2 || z1.Sizes[1] != 1 {
+		t.Errorf("zoom 1 stats = %+v", z1)
+	}
+	if z2 := stats[1]; z2.Zoom != 2 || z2.Tiles != 1 || z2.Bytes != 2000 {
+		t.Errorf("zoom 2 stats = %+v", z2)
+	}
+}
+
+func TestVerifyReportsProblems(t *testing.T) {
+	archive := buildArchive(t, []EntryV3{
+		{TileID: ZxyToID(1, 0, 0), Offset: 0, Length: 3, RunLength: 2},
+		{TileID: ZxyToID(2, 0, 0), Offset: 3, Length: 9, RunLength: 1}, // past the tile data
+	}, []byte("aaabb"))
+
+	res, err := Verify(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"header bounds are not set",
+		"metadata has no vector_layers",
+		"is outside the tile data section",
+		"is not a valid vector tile",
+		"header addresses 0 tiles, directories address 3",
+	} {
+		found := false
+		for _, p := range res.Problems {
+			found = found || strings.Contains(p, want)
+		}
+		if !found {
+			t.Errorf("no problem containing %q in %q", want, res.Problems)
+		}
+	}
+
+	// A truncated file is caught before any section is read
+	res, err = Verify(bytes.NewReader(archive[:len(archive)-1]), int64(len(archive)-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Problems) == 0 || !strings.Contains(res.Problems[0], "extends past the end") {
+		t.Errorf("truncated archive problems = %q", res.Problems)
+	}
+}
+
+func TestReaderRejectsSectionsPastEnd(t *testing.T) {
+	archive := buildArchive(t, []EntryV3{
+		{TileID: ZxyToID(1, 0, 0), Offset: 0, Length: 3, RunLength: 1},
+	}, []byte("aaa"))
+
+	// A root directory claiming more bytes than the file holds
+	h, err := DeserializeHeader(archive[:HeaderV3LenBytes])
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.RootLength = 1 << 40
+	bad := append(SerializeHeader(h), archive[HeaderV3LenBytes:]...)
+	if _, err := NewReader(bytes.NewReader(bad), int64(len(bad))); err == nil || !strings.Contains(err.Error(), "past the end") {
+		t.Errorf("huge root directory: err = %v", err)
+	}
+
+	// Metadata and tiles past the end fail when read
+	h, _ = DeserializeHeader(archive[:HeaderV3LenBytes])
+	h.MetadataLength = 1<<64 - 1
+	h.TileDataOffset = 1<<64 - 2
+	bad = append(SerializeHeader(h), archive[HeaderV3LenBytes:]...)
+	r, err := NewReader(bytes.NewReader(bad), int64(len(bad)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Metadata(); err == nil || !strings.Contains(err.Error(), "past the end") {
+		t.Errorf("huge metadata: err = %v", err)
+	}
+	if _, _, err := r.Tile(1, 0, 0); err == nil || !strings.Contains(err.Error(), "past the end") {
+		t.Errorf("tile data past the end: err = %v", err)
+	}
+
+	// A reader told the archive is shorter than the header says
+	if _, err := NewReader(bytes.NewReader(archive), HeaderV3LenBytes); err == nil {
+		t.Error("root directory past the given size was read")
+	}
+}
+
+func TestDeserializeEntriesFirstOffsetZero(t *testing.T) {
+	// One entry: tile ID 0, run length 1, length 5, offset 0
+	raw := []byte{1, 0, 1, 5, 0}
+	if _, err := DeserializeEntries(raw, NoCompression); err == nil || !strings.Contains(err.Error(), "corrupt directory") {
+		t.Errorf("first entry without an offset: err = %v", err)
+	}
+
+	// A zero offset after the first entry continues the previous one
+	raw = []byte{2, 0, 1, 1, 1, 5, 7, 1, 0}
+	entries, err := DeserializeEntries(raw, NoCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Offset != 0 || entries[1].Offset != 5 {
+		t.Errorf("offsets = %d, %d; want 0, 5", entries[0].Offset, entries[1].Offset)
+	}
+}
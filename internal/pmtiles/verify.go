@@ -0,0 +1,394 @@
+package pmtiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/paulmach/orb/encoding/mvt"
+)
+
+// SizeBuckets are the upper bounds of the tile size histogram in
+// ZoomStats. Tiles larger than the last bound fall in a final bucket.
+var SizeBuckets = []uint32{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// FormatSize returns a human-readable size, such as "5.4 MB".
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ZoomStats summarizes the tiles of one zoom level.
+type ZoomStats struct {
+	Zoom     uint8
+	Tiles    uint64   // addressed tiles, counting each tile of a run
+	Bytes    uint64   // total stored size of the addressed tiles
+	MaxBytes uint32   // largest tile
+	Sizes    []uint64 // tile counts per SizeBuckets bucket, plus one for larger tiles
+}
+
+// Walk calls fn for each tile entry in tile ID order, reading leaf
+// directories as needed.
+func (rd *Reader) Walk(fn func(EntryV3) error) error {
+	return rd.walk(rd.root, 0, fn)
+}
+
+func (rd *Reader) walk(entries []EntryV3, depth int, fn func(EntryV3) error) error {
+	if depth >= maxDirectoryDepth {
+		return errors.New("leaf directories nested too deeply")
+	}
+	for _, e := range entries {
+		if e.RunLength > 0 {
+			if err := fn(e); err != nil {
+				return err
+			}
+			continue
+		}
+		leaf, err := rd.leaf(e.Offset, uint64(e.Length))
+		if err != nil {
+			return err
+		}
+		if err := rd.walk(leaf, depth+1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ZoomStats returns tile counts and size histograms per zoom level, for
+// the zoom levels that have tiles.
+func (rd *Reader) ZoomStats() ([]ZoomStats, error) {
+	byZoom := make(map[uint8]*ZoomStats)
+	err := rd.Walk(func(e EntryV3) error {
+		// A run of identical tiles can cross into the next zoom level
+		for id, end := e.TileID, e.TileID+uint64(e.RunLength); id < end; {
+			z, _, _ := IDToZxy(id)
+			next := min(end, ZxyToID(z+1, 0, 0))
+			s, ok := byZoom[z]
+			if !ok {
+				s = &ZoomStats{Zoom: z, Sizes: make([]uint64, len(SizeBuckets)+1)}
+				byZoom[z] = s
+			}
+			n := next - id
+			s.Tiles += n
+			s.Bytes += n * uint64(e.Length)
+			s.MaxBytes = max(s.MaxBytes, e.Length)
+			s.Sizes[sort.Search(len(SizeBuckets), func(i int) bool { return e.Length <= SizeBuckets[i] })] += n
+			id = next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]ZoomStats, 0, len(byZoom))
+	for _, s := range byZoom {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Zoom < stats[j].Zoom })
+	return stats, nil
+}
+
+// maxProblems bounds the problems Verify reports.
+const maxProblems = 100
+
+// VerifyResult is the outcome of Verify.
+type VerifyResult struct {
+	Entries  uint64   // tile entries walked
+	Tiles    uint64   // distinct tile contents checked
+	Problems []string // empty when the archive is valid
+}
+
+// Verify checks a PMTiles archive of the given size: that the header's
+// sections, bounds and zooms are sane, every directory entry is sorted and
+// in range, every tile decompresses (and decodes, for MVT), and the header
+// counters match the directories. Problems are reported in the result; the
+// error is only set when the header can't be read.
+func Verify(r io.ReaderAt, size int64) (VerifyResult, error) {
+	buf := make([]byte, HeaderV3LenBytes)
+	if _, err := r.ReadAt(buf, 0); err != nil {
+		return VerifyResult{}, fmt.Errorf("reading header: %w", err)
+	}
+	header, err := DeserializeHeader(buf)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+
+	v := &verifier{
+		r:        r,
+		h:        header,
+		contents: make(map[uint64]uint32),
+	}
+	if !v.header(uint64(size)) {
+		// Sections can't be trusted, so don't read them
+		return v.res, nil
+	}
+	v.metadata()
+	if root, ok := v.directory("root directory", header.RootOffset, header.RootLength); ok {
+		v.walk(root, 0)
+	}
+	v.counters()
+	return v.res, nil
+}
+
+// verifier accumulates the state of one Verify run.
+type verifier struct {
+	r   io.ReaderAt
+	h   HeaderV3
+	res VerifyResult
+
+	lastEnd    uint64            // tile ID after the previous entry's run
+	addressed  uint64            // tiles addressed by the entries so far
+	contents   map[uint64]uint32 // tile data offset to length
+	nextOffset uint64            // where the next new tile starts when clustered
+	unordered  bool              // tile data is out of order for a clustered archive
+}
+
+func (v *verifier) problemf(format string, args ...any) {
+	switch n := len(v.res.Problems); {
+	case n < maxProblems:
+		v.res.Problems = append(v.res.Problems, fmt.Sprintf(format, args...))
+	case n == maxProblems:
+		v.res.Problems = append(v.res.Problems, "too many problems; stopping the report here")
+	}
+}
+
+// header checks the header fields that don't need other sections,
+// reporting whether the sections can be read.
+func (v *verifier) header(size uint64) bool {
+	h := v.h
+	sectionsOK := true
+	if h.SpecVersion != 3 {
+		v.problemf("spec version is %d, want 3", h.SpecVersion)
+	}
+
+	sections := []struct {
+		name           string
+		offset, length uint64
+	}{
+		{"root directory", h.RootOffset, h.RootLength},
+		{"metadata", h.MetadataOffset, h.MetadataLength},
+		{"leaf directories", h.LeafDirectoryOffset, h.LeafDirectoryLength},
+		{"tile data", h.TileDataOffset, h.TileDataLength},
+	}
+	for _, s := range sections {
+		if s.offset < HeaderV3LenBytes && s.length > 0 {
+			v.problemf("%s at offset %d overlaps the header", s.name, s.offset)
+			sectionsOK = false
+		}
+		if s.offset+s.length < s.offset || s.offset+s.length > size {
+			v.problemf("%s (offset %d, length %d) extends past the end of the %d byte file", s.name, s.offset, s.length, size)
+			sectionsOK = false
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].offset < sections[j].offset })
+	for i := 1; i < len(sections); i++ {
+		prev, s := sections[i-1], sections[i]
+		if prev.length > 0 && s.length > 0 && prev.offset+prev.length > s.offset {
+			v.problemf("%s overlaps %s", prev.name, s.name)
+		}
+	}
+	if h.RootOffset+h.RootLength > 16384 {
+		v.problemf("root directory ends at byte %d, past the first 16 KiB; it needs leaf directories", h.RootOffset+h.RootLength)
+	}
+	if h.RootLength == 0 {
+		v.problemf("root directory is empty")
+	}
+
+	if h.InternalCompression == UnknownCompression || h.InternalCompression > Zstd {
+		v.problemf("unknown internal compression %d", h.InternalCompression)
+	}
+	if h.TileCompression > Zstd {
+		v.problemf("unknown tile compression %d", h.TileCompression)
+	}
+	if h.TileType > Avif {
+		v.problemf("unknown tile type %d", h.TileType)
+	}
+
+	if h.MinZoom > h.MaxZoom {
+		v.problemf("min zoom %d is above max zoom %d", h.MinZoom, h.MaxZoom)
+	}
+	if h.MinLonE7 == 0 && h.MinLatE7 == 0 && h.MaxLonE7 == 0 && h.MaxLatE7 == 0 {
+		v.problemf("header bounds are not set")
+	} else {
+		if h.MinLonE7 > h.MaxLonE7 || h.MinLatE7 > h.MaxLatE7 {
+			v.problemf("header bounds are inverted")
+		}
+		if h.MinLonE7 < -180e7 || h.MaxLonE7 > 180e7 || h.MinLatE7 < -90e7 || h.MaxLatE7 > 90e7 {
+			v.problemf("header bounds are outside longitude/latitude range")
+		}
+		if h.CenterLonE7 < h.MinLonE7 || h.CenterLonE7 > h.MaxLonE7 || h.CenterLatE7 < h.MinLatE7 || h.CenterLatE7 > h.MaxLatE7 {
+			v.problemf("header center is outside the bounds")
+		}
+	}
+	if h.CenterZoom < h.MinZoom || h.CenterZoom > h.MaxZoom {
+		v.problemf("center zoom %d is outside zooms %d-%d", h.CenterZoom, h.MinZoom, h.MaxZoom)
+	}
+	return sectionsOK
+}
+
+// metadata checks that the metadata decodes as a JSON object.
+func (v *verifier) metadata() {
+	data, err := v.section(v.h.MetadataOffset, v.h.MetadataLength)
+	if err != nil {
+		v.problemf("reading metadata: %v", err)
+		return
+	}
+	raw, err := Decompress(data, v.h.InternalCompression)
+	if err != nil {
+		v.problemf("decompressing metadata: %v", err)
+		return
+	}
+	metadata := make(map[string]any)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &metadata); err != nil {
+			v.problemf("parsing metadata: %v", err)
+			return
+		}
+	}
+	if _, ok := metadata["vector_layers"]; !ok && v.h.TileType == Mvt {
+		v.problemf("metadata has no vector_layers")
+	}
+}
+
+// directory reads and decodes a directory at an absolute offset.
+func (v *verifier) directory(name string, offset, length uint64) ([]EntryV3, bool) {
+	data, err := v.section(offset, length)
+	if err == nil {
+		var entries []EntryV3
+		if entries, err = DeserializeEntries(data, v.h.InternalCompression); err == nil {
+			if len(entries) == 0 {
+				v.problemf("%s at offset %d has no entries", name, offset)
+			}
+			return entries, true
+		}
+	}
+	v.problemf("reading %s at offset %d: %v", name, offset, err)
+	return nil, false
+}
+
+// walk checks a directory's entries in order, descending into leaves.
+func (v *verifier) walk(entries []EntryV3, depth int) {
+	for i, e := range entries {
+		if i > 0 && e.TileID <= entries[i-1].TileID {
+			v.problemf("directory entries are not sorted: tile ID %d follows %d", e.TileID, entries[i-1].TileID)
+		}
+		if e.RunLength == 0 {
+			v.leaf(e, depth)
+		} else {
+			v.tile(e)
+		}
+	}
+}
+
+// leaf checks a leaf directory entry and walks the leaf.
+func (v *verifier) leaf(e EntryV3, depth int) {
+	if depth+1 >= maxDirectoryDepth {
+		v.problemf("leaf directory at offset %d is nested too deeply", e.Offset)
+		return
+	}
+	if e.Length == 0 || e.Offset+uint64(e.Length) > v.h.LeafDirectoryLength {
+		v.problemf("leaf directory (offset %d, length %d) is outside the leaf directory section", e.Offset, e.Length)
+		return
+	}
+	leaf, ok := v.directory("leaf directory", v.h.LeafDirectoryOffset+e.Offset, uint64(e.Length))
+	if !ok {
+		return
+	}
+	if len(leaf) > 0 && leaf[0].TileID < e.TileID {
+		v.problemf("leaf directory for tile ID %d starts at tile ID %d", e.TileID, leaf[0].TileID)
+	}
+	v.walk(leaf, depth+1)
+}
+
+// tile checks a tile entry and, the first time its data is seen, the tile.
+func (v *verifier) tile(e EntryV3) {
+	v.res.Entries++
+	v.addressed += uint64(e.RunLength)
+
+	end := e.TileID + uint64(e.RunLength)
+	if e.TileID < v.lastEnd {
+		v.problemf("tile ID %d overlaps the previous entry's run", e.TileID)
+	}
+	v.lastEnd = end
+	if z, _, _ := IDToZxy(e.TileID); z < v.h.MinZoom || z > v.h.MaxZoom {
+		v.problemf("tile ID %d is at zoom %d, outside zooms %d-%d", e.TileID, z, v.h.MinZoom, v.h.MaxZoom)
+	} else if z, _, _ := IDToZxy(end - 1); z > v.h.MaxZoom {
+		v.problemf("run of tile ID %d reaches zoom %d, past max zoom %d", e.TileID, z, v.h.MaxZoom)
+	}
+
+	if e.Length == 0 || e.Offset+uint64(e.Length) > v.h.TileDataLength {
+		v.problemf("tile ID %d (offset %d, length %d) is outside the tile data section", e.TileID, e.Offset, e.Length)
+		return
+	}
+	if length, seen := v.contents[e.Offset]; seen {
+		if length != e.Length {
+			v.problemf("tile ID %d reuses offset %d with length %d, not %d", e.TileID, e.Offset, e.Length, length)
+		}
+		return
+	}
+	v.contents[e.Offset] = e.Length
+	if v.h.Clustered && e.Offset != v.nextOffset && !v.unordered {
+		v.unordered = true
+		v.problemf("header says clustered, but tile ID %d is at offset %d, not %d", e.TileID, e.Offset, v.nextOffset)
+	}
+	v.nextOffset = e.Offset + uint64(e.Length)
+
+	v.res.Tiles++
+	data, err := v.section(v.h.TileDataOffset+e.Offset, uint64(e.Length))
+	if err != nil {
+		v.problemf("reading tile ID %d: %v", e.TileID, err)
+		return
+	}
+	if data, err = Decompress(data, v.h.TileCompression); err != nil {
+		v.problemf("decompressing tile ID %d: %v", e.TileID, err)
+		return
+	}
+	if v.h.TileType == Mvt {
+		if _, err := mvt.Unmarshal(data); err != nil {
+			z, x, y := IDToZxy(e.TileID)
+			v.problemf("tile %d/%d/%d is not a valid vector tile: %v", z, x, y, err)
+		}
+	}
+}
+
+// counters compares the header's counters with what the walk found.
+func (v *verifier) counters() {
+	h := v.h
+	if h.AddressedTilesCount != v.addressed {
+		v.problemf("header addresses %d tiles, directories address %d", h.AddressedTilesCount, v.addressed)
+	}
+	if h.TileEntriesCount != v.res.Entries {
+		v.problemf("header counts %d tile entries, directories have %d", h.TileEntriesCount, v.res.Entries)
+	}
+	if h.TileContentsCount != v.res.Tiles {
+		v.problemf("header counts %d tile contents, directories reference %d", h.TileContentsCount, v.res.Tiles)
+	}
+	if h.Clustered && !v.unordered && v.nextOffset != h.TileDataLength {
+		v.problemf("tile data is %d bytes, but tiles end at byte %d", h.TileDataLength, v.nextOffset)
+	}
+}
+
+// section reads length bytes at an absolute offset.
+func (v *verifier) section(offset, length uint64) ([]byte, error) {
+	buf := make([]byte, length)
+	n, err := v.r.ReadAt(buf, int64(offset))
+	if n == len(buf) {
+		return buf, nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
@@ -7,6 +7,9 @@ import (
 	"log"
 	"net/http"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
@@ -19,6 +22,7 @@ import (
 	"github.com/joeblew999/plat-geo/internal/api"
 	"github.com/joeblew999/plat-geo/internal/api/editor"
 	"github.com/joeblew999/plat-geo/internal/db"
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
 	"github.com/joeblew999/plat-geo/internal/service"
 	"github.com/joeblew999/plat-geo/internal/humastar"
 )
@@ -31,6 +35,10 @@ type Config struct {
 	WebDir  string
 }
 
+// jobWorkers is how many tile generation jobs run at once. Tiling is CPU
+// and disk heavy, and each engine already uses every core.
+const jobWorkers = 2
+
 // Server is the geo HTTP server.
 type Server struct {
 	config         Config
@@ -62,14 +70,20 @@ func New(cfg Config) *Server {
 		&huma.Tag{Name: "layers", Description: "Layer management operations"},
 		&huma.Tag{Name: "sources", Description: "Source file management"},
 		&huma.Tag{Name: "tiles", Description: "Tile serving and management"},
+		&huma.Tag{Name: "jobs", Description: "Background tile generation jobs"},
 		&huma.Tag{Name: "database", Description: "Database query endpoints"},
 		&huma.Tag{Name: "editor", Description: "Editor SSE endpoints (Datastar)"},
 	)
 
+	layerService := service.NewLayerService(cfg.DataDir)
+	tileService := service.NewTileService(cfg.DataDir, layerService)
+	tilerService := service.NewTilerService(cfg.DataDir, tileService)
 	services := &api.Services{
-		Layer:  service.NewLayerService(cfg.DataDir),
-		Tile:   service.NewTileService(cfg.DataDir),
+		Layer:  layerService,
+		Tile:   tileService,
 		Source: service.NewSourceService(cfg.DataDir),
+		Tiler:  tilerService,
+		Job:    service.NewJobService(cfg.DataDir, tilerService),
 	}
 
 	var renderer *humastar.Renderer
@@ -132,7 +146,14 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
 }
 
+// Start starts background work: the tile generation job workers.
+func (s *Server) Start() {
+	s.services.Job.Start(jobWorkers)
+}
+
 func (s *Server) Close() error {
+	s.services.Job.Close()
+	s.services.Tile.Close()
 	return db.Close()
 }
 
@@ -161,13 +182,13 @@ func (s *Server) routes() {
 		layerHandler := editor.NewLayerHandler(s.services.Layer, s.renderer)
 		huma.AutoRegister(s.humaAPI, layerHandler)
 
-		tileHandler := editor.NewTileHandler(s.services.Tile, service.NewTilerService(s.config.DataDir), s.renderer)
+		tileHandler := editor.NewTileHandler(s.services.Tile, s.services.Job, s.renderer)
 		huma.AutoRegister(s.humaAPI, tileHandler)
 
 		sourceHandler := editor.NewSourceHandler(s.services.Source, s.renderer)
 		huma.AutoRegister(s.humaAPI, sourceHandler)
 
-		eventHandler := editor.NewEventHandler(s.services.Layer, s.renderer)
+		eventHandler := editor.NewEventHandler(s.services.Layer, s.services.Tile, s.renderer)
 		huma.AutoRegister(s.humaAPI, eventHandler)
 	}
 
@@ -272,6 +293,86 @@ func (s *Server) handleTiles(tilesDir string) http.Handler {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
+		// Hidden files are archives and spools still being generated
+		for _, part := range strings.Split(r.URL.Path, "/") {
+			if strings.HasPrefix(part, ".") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		if m := tilePath.FindStringSubmatch(r.URL.Path); m != nil {
+			s.serveTile(w, r, m)
+			return
+		}
 		http.FileServer(http.Dir(tilesDir)).ServeHTTP(w, r)
 	})
 }
+
+// tilePath matches z/x/y tile requests such as buildings/14/8192/5461.mvt,
+// capturing the archive, z, x and y.
+var tilePath = regexp.MustCompile(`^(.+?)(?:\.pmtiles)?/(\d+)/(\d+)/(\d+)\.(?:mvt|pbf|png|jpg|jpeg|webp|avif)$`)
+
+// serveTile serves one tile out of a PMTiles archive for clients that
+// don't speak PMTiles range requests. Compressed tiles are passed through
+// when the client accepts their encoding and decompressed otherwise, and
+// empty tiles are answered with 204 No Content.
+func (s *Server) serveTile(w http.ResponseWriter, r *http.Request, m []string) {
+	z, errZ := strconv.ParseUint(m[2], 10, 8)
+	x, errX := strconv.ParseUint(m[3], 10, 32)
+	y, errY := strconv.ParseUint(m[4], 10, 32)
+	if errZ != nil || errX != nil || errY != nil || z > 31 || x >= 1<<z || y >= 1<<z {
+		http.Error(w, "invalid tile coordinates", http.StatusBadRequest)
+		return
+	}
+
+	reader, release, err := s.services.Tile.Archive(m[1] + ".pmtiles")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer release()
+
+	data, ok, err := reader.Tile(uint8(z), uint32(x), uint32(y))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	if !ok || len(data) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header := reader.Header()
+	w.Header().Set("Content-Type", header.TileType.MediaType())
+	if c := header.TileCompression; c != pmtiles.NoCompression && c != pmtiles.UnknownCompression {
+		if acceptsEncoding(r, c.String()) {
+			w.Header().Set("Content-Encoding", c.String())
+		} else if data, err = pmtiles.Decompress(data, c); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// acceptsEncoding reports whether the request's Accept-Encoding allows
+// the given content coding.
+func acceptsEncoding(r *http.Request, coding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) && strings.TrimSpace(name) != "*" {
+			continue
+		}
+		// An explicit q=0 refuses the coding
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(v, 64)
+		}
+		return q > 0
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/joeblew999/plat-geo/internal/api"
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+	"github.com/joeblew999/plat-geo/internal/service"
+)
+
+// tilePayload is the content of the only tile, 0/0/0, in the test archive.
+var tilePayload = []byte("not really a vector tile")
+
+// writeTestArchive writes an archive holding tilePayload, gzipped, as tile
+// 0/0/0 and no other tiles.
+func writeTestArchive(t *testing.T, path string) {
+	t.Helper()
+
+	tileData, err := pmtiles.Compress(tilePayload, pmtiles.Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootBytes := pmtiles.SerializeEntries([]pmtiles.EntryV3{
+		{TileID: pmtiles.ZxyToID(0, 0, 0), Offset: 0, Length: uint32(len(tileData)), RunLength: 1},
+	}, pmtiles.Gzip)
+	metadataBytes, err := pmtiles.SerializeMetadata(map[string]any{"name": "roads"}, pmtiles.Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := pmtiles.HeaderV3{
+		SpecVersion:         3,
+		RootOffset:          pmtiles.HeaderV3LenBytes,
+		RootLength:          uint64(len(rootBytes)),
+		InternalCompression: pmtiles.Gzip,
+		TileCompression:     pmtiles.Gzip,
+		TileType:            pmtiles.Mvt,
+		MaxZoom:             1,
+		AddressedTilesCount: 1,
+		TileEntriesCount:    1,
+		TileContentsCount:   1,
+	}
+	header.MetadataOffset = header.RootOffset + header.RootLength
+	header.MetadataLength = uint64(len(metadataBytes))
+	header.LeafDirectoryOffset = header.MetadataOffset + header.MetadataLength
+	header.TileDataOffset = header.LeafDirectoryOffset
+	header.TileDataLength = uint64(len(tileData))
+
+	var b bytes.Buffer
+	b.Write(pmtiles.SerializeHeader(header))
+	b.Write(rootBytes)
+	b.Write(metadataBytes)
+	b.Write(tileData)
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTileServer returns the /tiles/ handler over a data directory holding
+// the test archive as roads.pmtiles.
+func newTileServer(t *testing.T) http.Handler {
+	t.Helper()
+	dataDir := t.TempDir()
+	tilesDir := filepath.Join(dataDir, "tiles")
+	if err := os.MkdirAll(tilesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestArchive(t, filepath.Join(tilesDir, "roads.pmtiles"))
+
+	tiles := service.NewTileService(dataDir, service.NewLayerService(dataDir))
+	t.Cleanup(tiles.Close)
+	s := &Server{services: &api.Services{Tile: tiles}}
+	return http.StripPrefix("/tiles/", s.handleTiles(tilesDir))
+}
+
+func getTile(h http.Handler, method, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeTileEncoding(t *testing.T) {
+	h := newTileServer(t)
+	gzipped, err := pmtiles.Compress(tilePayload, pmtiles.Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, acceptEncoding string
+		wantEncoding         string
+		wantBody             []byte
+	}{
+		{"/tiles/roads/0/0/0.mvt", "gzip, deflate, br", "gzip", gzipped},
+		{"/tiles/roads.pmtiles/0/0/0.pbf", "gzip", "gzip", gzipped},
+		{"/tiles/roads/0/0/0.mvt", "", "", tilePayload},
+		{"/tiles/roads/0/0/0.mvt", "br", "", tilePayload},
+		{"/tiles/roads/0/0/0.mvt", "gzip;q=0", "", tilePayload},
+	}
+	for _, tt := range tests {
+		rec := getTile(h, http.MethodGet, tt.path, tt.acceptEncoding)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s (Accept-Encoding %q): status %d, want 200", tt.path, tt.acceptEncoding, rec.Code)
+			continue
+		}
+		if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+			t.Errorf("%s (Accept-Encoding %q): Content-Encoding %q, want %q", tt.path, tt.acceptEncoding, got, tt.wantEncoding)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), tt.wantBody) {
+			t.Errorf("%s (Accept-Encoding %q): body %q, want %q", tt.path, tt.acceptEncoding, rec.Body.Bytes(), tt.wantBody)
+		}
+		if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len(tt.wantBody)) {
+			t.Errorf("%s (Accept-Encoding %q): Content-Length %s, want %d", tt.path, tt.acceptEncoding, got, len(tt.wantBody))
+		}
+		if got := rec.Header().Get("Content-Type"); got != pmtiles.Mvt.MediaType() {
+			t.Errorf("%s: Content-Type %q", tt.path, got)
+		}
+		if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s: Vary %q", tt.path, got)
+		}
+	}
+
+	// HEAD answers with the headers only
+	rec := getTile(h, http.MethodHead, "/tiles/roads/0/0/0.mvt", "")
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != strconv.Itoa(len(tilePayload)) {
+		t.Errorf("HEAD: status %d, %d body bytes, Content-Length %s", rec.Code, rec.Body.Len(), rec.Header().Get("Content-Length"))
+	}
+}
+
+func TestServeTileStatus(t *testing.T) {
+	h := newTileServer(t)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/tiles/roads/1/1/1.mvt", http.StatusNoContent}, // inside the archive's range, but empty
+		{"/tiles/roads/5/3/7.mvt", http.StatusNoContent}, // past MaxZoom
+		{"/tiles/roads/1/2/0.mvt", http.StatusBadRequest},
+		{"/tiles/roads/1/0/2.mvt", http.StatusBadRequest},
+		{"/tiles/roads/0/1/0.mvt", http.StatusBadRequest},
+		{"/tiles/roads/32/0/0.mvt", http.StatusBadRequest},
+		{"/tiles/roads/256/0/0.mvt", http.StatusBadRequest},
+		{"/tiles/roads/1/0/99999999999.mvt", http.StatusBadRequest},
+		{"/tiles/missing/0/0/0.mvt", http.StatusNotFound},
+		{"/tiles/.roads/0/0/0.mvt", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := getTile(h, http.MethodGet, tt.path, "gzip")
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.path, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusNoContent && rec.Body.Len() != 0 {
+			t.Errorf("%s: %d body bytes in an empty tile", tt.path, rec.Body.Len())
+		}
+	}
+}
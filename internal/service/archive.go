@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+)
+
+// maxOpenArchives bounds the archives kept open at once. Opening one
+// costs a stat, a header read and decoding the root directory, so a
+// small working set of tilesets covers nearly every request.
+const maxOpenArchives = 32
+
+// archiveCache keeps PMTiles archives open between tile requests, along
+// with the directories each reader has decoded. An archive whose file has
+// been replaced is reopened, and the old reader closed once no request
+// still uses it. Past maxOpenArchives the least recently used archive is
+// dropped.
+type archiveCache struct {
+	mu       sync.Mutex
+	archives map[string]*cachedArchive
+	clock    uint64 // ticks once per lookup, to order archives by use
+}
+
+type cachedArchive struct {
+	reader   *pmtiles.Reader
+	info     os.FileInfo
+	refs     int
+	stale    bool
+	lastUsed uint64
+}
+
+// Archive returns an open reader for a PMTiles archive in the tiles
+// directory, such as "buildings.pmtiles" or a version under "versions/".
+// The caller must call release when done with the reader.
+func (s *TileService) Archive(name string) (reader *pmtiles.Reader, release func(), err error) {
+	if err := validArchivePath(name); err != nil {
+		return nil, nil, err
+	}
+	path := filepath.Join(s.tilesDir, filepath.FromSlash(name))
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tileset %q not found", name)
+	}
+
+	c := &s.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	a, ok := c.archives[name]
+	if ok && !os.SameFile(a.info, info) {
+		c.drop(name, a)
+		ok = false
+	}
+	if !ok {
+		r, err := pmtiles.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening %s: %w", name, err)
+		}
+		if c.archives == nil {
+			c.archives = make(map[string]*cachedArchive)
+		}
+		if len(c.archives) >= maxOpenArchives {
+			c.dropLeastRecent()
+		}
+		a = &cachedArchive{reader: r, info: info}
+		c.archives[name] = a
+	}
+
+	c.clock++
+	a.lastUsed = c.clock
+	a.refs++
+	return a.reader, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		a.refs--
+		if a.stale && a.refs == 0 {
+			a.reader.Close()
+		}
+	}, nil
+}
+
+// drop removes an archive from the cache, closing it once unused.
+// The caller must hold c.mu.
+func (c *archiveCache) drop(name string, a *cachedArchive) {
+	delete(c.archives, name)
+	a.stale = true
+	if a.refs == 0 {
+		a.reader.Close()
+	}
+}
+
+// dropLeastRecent drops the archive used longest ago. The caller must
+// hold c.mu.
+func (c *archiveCache) dropLeastRecent() {
+	var oldest string
+	for name, a := range c.archives {
+		if oldest == "" || a.lastUsed < c.archives[oldest].lastUsed {
+			oldest = name
+		}
+	}
+	if oldest != "" {
+		c.drop(oldest, c.archives[oldest])
+	}
+}
+
+// evict drops the cached readers of a tileset and of its versions, for
+// when their files are replaced, moved or removed.
+func (c *archiveCache) evict(name string) {
+	versions := versionsDir + "/" + strings.TrimSuffix(name, ".pmtiles") + "/"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, a := range c.archives {
+		if n == name || strings.HasPrefix(n, versions) {
+			c.drop(n, a)
+		}
+	}
+}
+
+// Close closes the cached archives.
+func (s *TileService) Close() {
+	c := &s.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, a := range c.archives {
+		c.drop(name, a)
+	}
+}
+
+// validArchivePath rejects archive paths outside the tiles directory or
+// naming hidden files still being generated.
+func validArchivePath(name string) error {
+	if filepath.Ext(name) != ".pmtiles" {
+		return fmt.Errorf("invalid tileset name %q", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || strings.HasPrefix(part, ".") || strings.Contains(part, `\`) {
+			return fmt.Errorf("invalid tileset name %q", name)
+		}
+	}
+	return nil
+}
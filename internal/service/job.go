@@ -0,0 +1,477 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobState is the lifecycle state of a background job.
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// Finished reports whether the job has stopped running for good.
+func (s JobState) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+const (
+	// maxJobLogs bounds the log lines kept per job.
+	maxJobLogs = 100
+	// maxFinishedJobs bounds the finished jobs kept in history.
+	maxFinishedJobs = 200
+	// maxQueuedJobs bounds the jobs waiting for a worker.
+	maxQueuedJobs = 1000
+)
+
+// JobRequest describes the work of a tile generation job: either a single
+// source or a multi-layer tileset.
+type JobRequest struct {
+	Generate *TileGenerateOptions `json:"generate,omitempty" doc:"Generate tiles from one source file"`
+	Layers   *TileLayersOptions   `json:"layers,omitempty" doc:"Generate a multi-layer tileset from several source files"`
+}
+
+// Validate checks that exactly one kind of work is requested.
+func (r JobRequest) Validate() error {
+	if (r.Generate == nil) == (r.Layers == nil) {
+		return fmt.Errorf("exactly one of generate or layers is required")
+	}
+	return nil
+}
+
+// Output returns the name of the tileset the job writes.
+func (r JobRequest) Output() string {
+	if r.Generate != nil {
+		return r.Generate.OutputName
+	}
+	if r.Layers != nil {
+		return r.Layers.OutputName
+	}
+	return ""
+}
+
+// Job is a background tile generation job.
+type Job struct {
+	ID         string     `json:"id" doc:"Job ID" example:"20260101-120000-a1b2c3"`
+	State      JobState   `json:"state" enum:"queued,running,succeeded,failed,cancelled" doc:"Job state"`
+	Request    JobRequest `json:"request" doc:"Requested work"`
+	Progress   int        `json:"progress" minimum:"0" maximum:"100" doc:"Progress percentage"`
+	Status     string     `json:"status,omitempty" doc:"Latest status message"`
+	Error      string     `json:"error,omitempty" doc:"Failure reason"`
+	Logs       []string   `json:"logs" doc:"Timestamped log lines, oldest first"`
+	Attempts   int        `json:"attempts" doc:"Times the job has been started"`
+	CreatedAt  time.Time  `json:"createdAt" doc:"When the job was submitted"`
+	StartedAt  *time.Time `json:"startedAt,omitempty" doc:"When the latest attempt started"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty" doc:"When the latest attempt finished"`
+}
+
+// JobService runs tile generation jobs on a bounded pool of workers.
+// Jobs are persisted to jobs.json in the data directory, so their history
+// survives restarts and unfinished jobs are queued again on startup.
+type JobService struct {
+	dataDir string
+	tiler   *TilerService
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc // running jobs
+	queue   chan string
+
+	ctx  context.Context // cancelled by Close
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewJobService creates a job service with the persisted job history.
+// Jobs only run once Start is called.
+func NewJobService(dataDir string, tiler *TilerService) *JobService {
+	ctx, stop := context.WithCancel(context.Background())
+	s := &JobService{
+		dataDir: dataDir,
+		tiler:   tiler,
+		jobs:    make(map[string]*Job),
+		cancels: make(map[string]context.CancelFunc),
+		queue:   make(chan string, maxQueuedJobs),
+		ctx:     ctx,
+		stop:    stop,
+	}
+	s.loadFromDisk()
+	return s
+}
+
+// Start queues jobs left unfinished by the last shutdown and starts a
+// pool of workers (at least one) to run jobs.
+func (s *JobService) Start(workers int) {
+	s.mu.Lock()
+	for _, job := range s.sorted() {
+		switch job.State {
+		case JobRunning:
+			job.State = JobQueued
+			s.logf(job, "Queued again after restart")
+		case JobQueued:
+			// Queuing twice is harmless: workers skip jobs no longer queued
+		default:
+			continue
+		}
+		if err := s.enqueue(job); err != nil {
+			s.finish(job, JobFailed, err.Error())
+		}
+	}
+	s.saveToDisk()
+	s.mu.Unlock()
+
+	for range max(workers, 1) {
+		s.wg.Add(1)
+		go s.worker()
+	}
+}
+
+// Close stops the workers. Running jobs are interrupted and left queued,
+// so the next Start runs them again.
+func (s *JobService) Close() {
+	s.stop()
+	s.wg.Wait()
+}
+
+// Submit validates a job's source and output names, queues it and
+// returns it.
+func (s *JobService) Submit(req JobRequest) (Job, error) {
+	if err := req.Validate(); err != nil {
+		return Job{}, err
+	}
+	if req.Generate != nil {
+		if _, err := s.tiler.checkGenerate(*req.Generate); err != nil {
+			return Job{}, err
+		}
+	} else if _, err := s.tiler.checkLayers(*req.Layers); err != nil {
+		return Job{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := &Job{
+		ID:        newJobID(),
+		State:     JobQueued,
+		Request:   req,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.logf(job, "Queued: generate %s", req.Output())
+	if err := s.enqueue(job); err != nil {
+		return Job{}, err
+	}
+	s.jobs[job.ID] = job
+	s.prune()
+	if err := s.saveToDisk(); err != nil {
+		return Job{}, err
+	}
+	DefaultBus.Publish(Event{Resource: "jobs", Action: "created", ID: job.ID})
+	return s.copy(job), nil
+}
+
+// List returns all jobs, newest first.
+func (s *JobService) List() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sorted := s.sorted()
+	out := make([]Job, len(sorted))
+	for i, job := range sorted {
+		out[len(sorted)-1-i] = s.copy(job)
+	}
+	return out
+}
+
+// ListPaged returns a page of jobs, newest first, with the total count.
+func (s *JobService) ListPaged(offset, limit int) ([]Job, int) {
+	all := s.List()
+	total := len(all)
+	if offset >= total {
+		return []Job{}, total
+	}
+	return all[offset:min(offset+limit, total)], total
+}
+
+// Get returns a job by ID.
+func (s *JobService) Get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return s.copy(job), true
+}
+
+// Cancel stops a queued or running job.
+func (s *JobService) Cancel(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("job %q not found", id)
+	}
+	switch job.State {
+	case JobQueued:
+		// The worker skips it when it comes off the queue
+		s.finish(job, JobCancelled, "")
+	case JobRunning:
+		if cancel, ok := s.cancels[id]; ok {
+			// The worker records the cancellation once the run stops
+			cancel()
+			s.logf(job, "Cancelling")
+		} else {
+			// Left running by a crash and not yet queued again by Start
+			s.finish(job, JobCancelled, "")
+		}
+	default:
+		return Job{}, fmt.Errorf("job %q is %s", id, job.State)
+	}
+	if err := s.saveToDisk(); err != nil {
+		return Job{}, err
+	}
+	return s.copy(job), nil
+}
+
+// Retry queues a failed or cancelled job again.
+func (s *JobService) Retry(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("job %q not found", id)
+	}
+	if job.State != JobFailed && job.State != JobCancelled {
+		return Job{}, fmt.Errorf("job %q is %s", id, job.State)
+	}
+	if err := s.enqueue(job); err != nil {
+		return Job{}, err
+	}
+	job.State = JobQueued
+	job.Progress = 0
+	job.Status = ""
+	job.Error = ""
+	job.StartedAt = nil
+	job.FinishedAt = nil
+	s.logf(job, "Queued for retry")
+	if err := s.saveToDisk(); err != nil {
+		return Job{}, err
+	}
+	DefaultBus.Publish(Event{Resource: "jobs", Action: "updated", ID: id})
+	return s.copy(job), nil
+}
+
+// enqueue hands a job ID to the workers without blocking.
+func (s *JobService) enqueue(job *Job) error {
+	select {
+	case s.queue <- job.ID:
+		return nil
+	default:
+		return fmt.Errorf("job queue is full (%d jobs waiting)", maxQueuedJobs)
+	}
+}
+
+// worker runs queued jobs until the service is closed.
+func (s *JobService) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case id := <-s.queue:
+			s.run(id)
+		}
+	}
+}
+
+// run executes one job if it is still queued.
+func (s *JobService) run(id string) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok || job.State != JobQueued {
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.cancels[id] = cancel
+	now := time.Now().UTC()
+	job.State = JobRunning
+	job.Attempts++
+	job.StartedAt = &now
+	s.logf(job, "Started (attempt %d)", job.Attempts)
+	req := job.Request
+	s.saveToDisk()
+	s.mu.Unlock()
+	DefaultBus.Publish(Event{Resource: "jobs", Action: "updated", ID: id})
+
+	progress := func(pct int, status string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// Log and persist only every 10%
+		step := pct/10 != job.Progress/10
+		job.Progress = pct
+		job.Status = status
+		if step {
+			s.logf(job, "%s", status)
+			s.saveToDisk()
+		}
+	}
+
+	var err error
+	if req.Generate != nil {
+		err = s.tiler.Generate(ctx, *req.Generate, progress)
+	} else {
+		err = s.tiler.GenerateLayers(ctx, *req.Layers, progress)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, id)
+	switch {
+	case err == nil:
+		s.finish(job, JobSucceeded, "")
+	case s.ctx.Err() != nil:
+		// Shutting down: the next Start runs it again
+		job.State = JobQueued
+		job.Progress = 0
+		job.Status = ""
+		job.StartedAt = nil
+		s.logf(job, "Interrupted by shutdown")
+		DefaultBus.Publish(Event{Resource: "jobs", Action: "updated", ID: id})
+	case ctx.Err() != nil:
+		s.finish(job, JobCancelled, "")
+	default:
+		s.finish(job, JobFailed, err.Error())
+	}
+	s.saveToDisk()
+}
+
+// finish records a job's final state. The caller holds s.mu and saves.
+func (s *JobService) finish(job *Job, state JobState, errMsg string) {
+	now := time.Now().UTC()
+	job.State = state
+	job.Error = errMsg
+	job.FinishedAt = &now
+	switch state {
+	case JobSucceeded:
+		job.Progress = 100
+		s.logf(job, "Succeeded")
+	case JobFailed:
+		s.logf(job, "Failed: %s", errMsg)
+	case JobCancelled:
+		s.logf(job, "Cancelled")
+	}
+	DefaultBus.Publish(Event{Resource: "jobs", Action: "updated", ID: job.ID})
+}
+
+// logf appends a timestamped line to a job's log. The caller holds s.mu.
+func (s *JobService) logf(job *Job, format string, args ...any) {
+	line := time.Now().UTC().Format(time.RFC3339) + " " + fmt.Sprintf(format, args...)
+	job.Logs = append(job.Logs, line)
+	if n := len(job.Logs); n > maxJobLogs {
+		job.Logs = slices.Clone(job.Logs[n-maxJobLogs:])
+	}
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs. The caller
+// holds s.mu.
+func (s *JobService) prune() {
+	finished := 0
+	sorted := s.sorted()
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if !sorted[i].State.Finished() {
+			continue
+		}
+		if finished++; finished > maxFinishedJobs {
+			delete(s.jobs, sorted[i].ID)
+		}
+	}
+}
+
+// sorted returns the jobs oldest first. The caller holds s.mu.
+func (s *JobService) sorted() []*Job {
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	slices.SortFunc(jobs, func(a, b *Job) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return jobs
+}
+
+// copy returns a snapshot of a job that is safe to use without s.mu.
+func (s *JobService) copy(job *Job) Job {
+	out := *job
+	out.Logs = slices.Clone(job.Logs)
+	return out
+}
+
+// jobsFile returns the path to the jobs state file.
+func (s *JobService) jobsFile() string {
+	return filepath.Join(s.dataDir, "jobs.json")
+}
+
+// loadFromDisk loads persisted jobs.
+func (s *JobService) loadFromDisk() {
+	data, err := os.ReadFile(s.jobsFile())
+	if err != nil {
+		return // File doesn't exist yet, start empty
+	}
+
+	var jobs []*Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return // Invalid JSON, start empty
+	}
+	for _, job := range jobs {
+		s.jobs[job.ID] = job
+	}
+}
+
+// saveToDisk persists all jobs. The caller holds s.mu. The file is
+// replaced atomically so a crash mid-write can't lose the history.
+func (s *JobService) saveToDisk() error {
+	if err := os.MkdirAll(s.dataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.jobsFile() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.jobsFile())
+}
+
+// newJobID returns a unique job ID that sorts by submission time.
+func newJobID() string {
+	var b [3]byte
+	rand.Read(b[:])
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+}
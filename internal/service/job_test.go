@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/plat-geo/internal/tiler"
+	"github.com/joeblew999/plat-geo/internal/tiler/gotiler"
+)
+
+// pointsGeoJSON is a small source the go engine tiles in milliseconds.
+const pointsGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"a"},"geometry":{"type":"Point","coordinates":[-122.4,37.8]}},
+{"type":"Feature","properties":{"name":"b"},"geometry":{"type":"Point","coordinates":[151.2,-33.9]}}]}`
+
+// writeSource writes pointsGeoJSON to the sources directory as name.
+func writeSource(t *testing.T, dataDir, name string) {
+	t.Helper()
+	dir := filepath.Join(dataDir, "sources")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(pointsGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// gatedTiler is the go engine held at the start of each run until release
+// is closed or the run is cancelled.
+type gatedTiler struct {
+	*gotiler.GoTiler
+	started chan string
+	release chan struct{}
+}
+
+func (g *gatedTiler) Tile(ctx context.Context, inputPath, outputPath string, config tiler.TileConfig, progress tiler.ProgressFunc) error {
+	g.started <- filepath.Base(inputPath)
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return g.GoTiler.Tile(ctx, inputPath, outputPath, config, progress)
+}
+
+// newTestTiler returns a tiler service over dataDir whose only engine is
+// a gated go engine.
+func newTestTiler(dataDir string) (*TilerService, *gatedTiler) {
+	gate := &gatedTiler{GoTiler: gotiler.New(), started: make(chan string, 10), release: make(chan struct{})}
+	s := NewTilerService(dataDir, NewTileService(dataDir, NewLayerService(dataDir)))
+	s.engines = []tiler.Tiler{gate}
+	return s, gate
+}
+
+func generateRequest(output string) JobRequest {
+	return JobRequest{Generate: &TileGenerateOptions{
+		SourceFile: "points.geojson",
+		OutputName: output,
+		LayerName:  "points",
+		MaxZoom:    2,
+	}}
+}
+
+// waitJob waits for a job to reach state.
+func waitJob(t *testing.T, s *JobService, id string, state JobState) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, ok := s.Get(id)
+		if !ok {
+			t.Fatalf("job %s not found", id)
+		}
+		if job.State == state {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s; logs: %v", id, job.State, state, job.Logs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobRunsToSuccess(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, gate := newTestTiler(dataDir)
+	close(gate.release)
+
+	s := NewJobService(dataDir, tilers)
+	s.Start(1)
+	defer s.Close()
+
+	job, err := s.Submit(generateRequest("points"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != JobQueued {
+		t.Errorf("submitted job is %s, want queued", job.State)
+	}
+
+	job = waitJob(t, s, job.ID, JobSucceeded)
+	if job.Progress != 100 || job.Attempts != 1 || job.FinishedAt == nil || job.Error != "" {
+		t.Errorf("finished job = progress %d, attempts %d, finishedAt %v, error %q", job.Progress, job.Attempts, job.FinishedAt, job.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "tiles", "points.pmtiles")); err != nil {
+		t.Errorf("archive not written: %v", err)
+	}
+}
+
+func TestJobSubmitValidatesNames(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, _ := newTestTiler(dataDir)
+	s := NewJobService(dataDir, tilers)
+
+	layers := func(source, output string) JobRequest {
+		return JobRequest{Layers: &TileLayersOptions{
+			OutputName: output,
+			Layers:     []TileLayerOptions{{SourceFile: source, LayerName: "points"}},
+		}}
+	}
+	tests := []struct {
+		name string
+		req  JobRequest
+	}{
+		{"no work", JobRequest{}},
+		{"both kinds", JobRequest{Generate: generateRequest("x").Generate, Layers: layers("points.geojson", "x").Layers}},
+		{"missing source", JobRequest{Generate: &TileGenerateOptions{SourceFile: "missing.geojson", OutputName: "x"}}},
+		{"source traversal", JobRequest{Generate: &TileGenerateOptions{SourceFile: "../points.geojson", OutputName: "x"}}},
+		{"source separator", JobRequest{Generate: &TileGenerateOptions{SourceFile: "sub/points.geojson", OutputName: "x"}}},
+		{"output traversal", generateRequest("../x")},
+		{"output separator", generateRequest("tiles/x")},
+		{"output backslash", generateRequest(`tiles\x`)},
+		{"hidden output", generateRequest(".x")},
+		{"layer source traversal", layers("../points.geojson", "x")},
+		{"layer output traversal", layers("points.geojson", "../x")},
+	}
+	for _, tt := range tests {
+		if _, err := s.Submit(tt.req); err == nil {
+			t.Errorf("%s: submitted, want an error", tt.name)
+		}
+	}
+	if jobs := s.List(); len(jobs) != 0 {
+		t.Errorf("%d jobs recorded for rejected requests", len(jobs))
+	}
+
+	if _, err := s.Submit(layers("points.geojson", "x")); err != nil {
+		t.Errorf("valid layers request: %v", err)
+	}
+}
+
+func TestJobCancelQueued(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, gate := newTestTiler(dataDir)
+	close(gate.release)
+	s := NewJobService(dataDir, tilers)
+
+	job, err := s.Submit(generateRequest("points"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, err = s.Cancel(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job.State != JobCancelled || job.FinishedAt == nil {
+		t.Errorf("cancelled job is %s, finishedAt %v", job.State, job.FinishedAt)
+	}
+	if _, err := s.Cancel(job.ID); err == nil {
+		t.Error("cancelling a cancelled job succeeded")
+	}
+
+	// Workers skip the cancelled job left on the queue
+	s.Start(1)
+	defer s.Close()
+	next, err := s.Submit(generateRequest("next"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitJob(t, s, next.ID, JobSucceeded)
+	if job, _ = s.Get(job.ID); job.State != JobCancelled || job.Attempts != 0 {
+		t.Errorf("cancelled job is %s after %d attempts", job.State, job.Attempts)
+	}
+}
+
+func TestJobCancelRunning(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, gate := newTestTiler(dataDir)
+	s := NewJobService(dataDir, tilers)
+	s.Start(1)
+	defer s.Close()
+
+	job, err := s.Submit(generateRequest("points"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-gate.started
+	if job, err = s.Cancel(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job.State != JobRunning {
+		t.Errorf("job is %s right after cancelling, want running until the run stops", job.State)
+	}
+
+	job = waitJob(t, s, job.ID, JobCancelled)
+	if job.Error != "" {
+		t.Errorf("cancelled job has error %q", job.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "tiles", "points.pmtiles")); !os.IsNotExist(err) {
+		t.Errorf("cancelled job wrote an archive: %v", err)
+	}
+}
+
+func TestJobPersistence(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, gate := newTestTiler(dataDir)
+	s := NewJobService(dataDir, tilers)
+
+	queued, err := s.Submit(generateRequest("queued"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := s.Submit(generateRequest("cancelled"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Cancel(cancelled.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "jobs.json")); err != nil {
+		t.Fatalf("jobs.json not written: %v", err)
+	}
+
+	reloaded := NewJobService(dataDir, tilers)
+	jobs := reloaded.List()
+	if len(jobs) != 2 {
+		t.Fatalf("reloaded %d jobs, want 2", len(jobs))
+	}
+	for _, want := range []Job{queued, cancelled} {
+		got, ok := reloaded.Get(want.ID)
+		if !ok {
+			t.Fatalf("job %s not reloaded", want.ID)
+		}
+		if got.Request.Output() != want.Request.Output() || !got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("reloaded job %s = %s at %v, want %s at %v", want.ID, got.Request.Output(), got.CreatedAt, want.Request.Output(), want.CreatedAt)
+		}
+	}
+	if got, _ := reloaded.Get(cancelled.ID); got.State != JobCancelled {
+		t.Errorf("reloaded cancelled job is %s", got.State)
+	}
+
+	// Start runs the reloaded queued job
+	close(gate.release)
+	reloaded.Start(1)
+	defer reloaded.Close()
+	waitJob(t, reloaded, queued.ID, JobSucceeded)
+}
+
+func TestJobShutdown(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, gate := newTestTiler(dataDir)
+	s := NewJobService(dataDir, tilers)
+	s.Start(1)
+
+	job, err := s.Submit(generateRequest("points"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-gate.started
+	s.Close()
+
+	job, _ = s.Get(job.ID)
+	if job.State != JobQueued || job.StartedAt != nil || job.Attempts != 1 {
+		t.Errorf("interrupted job is %s, startedAt %v, attempts %d; want queued, nil, 1", job.State, job.StartedAt, job.Attempts)
+	}
+	if last := job.Logs[len(job.Logs)-1]; !strings.HasSuffix(last, "Interrupted by shutdown") {
+		t.Errorf("last log line %q", last)
+	}
+
+	// The next start runs it again
+	close(gate.release)
+	restarted := NewJobService(dataDir, tilers)
+	if job, _ := restarted.Get(job.ID); job.State != JobQueued {
+		t.Errorf("reloaded interrupted job is %s, want queued", job.State)
+	}
+	restarted.Start(1)
+	defer restarted.Close()
+	if job = waitJob(t, restarted, job.ID, JobSucceeded); job.Attempts != 2 {
+		t.Errorf("job succeeded after %d attempts, want 2", job.Attempts)
+	}
+}
+
+func TestJobCancelAfterCrash(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSource(t, dataDir, "points.geojson")
+	tilers, _ := newTestTiler(dataDir)
+	s := NewJobService(dataDir, tilers)
+
+	job, err := s.Submit(generateRequest("points"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A crash leaves the job running in jobs.json with no worker behind it
+	s.mu.Lock()
+	s.jobs[job.ID].State = JobRunning
+	s.saveToDisk()
+	s.mu.Unlock()
+
+	reloaded := NewJobService(dataDir, tilers)
+	if job, err = reloaded.Cancel(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job.State != JobCancelled {
+		t.Errorf("job is %s, want cancelled", job.State)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
 )
 
 // SourceService manages source data files.
@@ -39,6 +41,9 @@ func (s *SourceService) List() ([]SourceFile, error) {
 		".shp":        "Shapefile",
 		".parquet":    "GeoParquet",
 		".geoparquet": "GeoParquet",
+		".geojsonl":   "GeoJSONSeq",
+		".geojsonseq": "GeoJSONSeq",
+		".fgb":        "FlatGeobuf",
 	}
 
 	var files []SourceFile
@@ -60,7 +65,7 @@ func (s *SourceService) List() ([]SourceFile, error) {
 
 		files = append(files, SourceFile{
 			Name:     entry.Name(),
-			Size:     formatSize(info.Size()),
+			Size:     pmtiles.FormatSize(info.Size()),
 			FileType: fileType,
 		})
 	}
@@ -96,6 +101,9 @@ var ValidExtensions = map[string]bool{
 	".json":       true,
 	".parquet":    true,
 	".geoparquet": true,
+	".geojsonl":   true,
+	".geojsonseq": true,
+	".fgb":        true,
 }
 
 // ValidateFilename checks if a filename is valid for upload.
@@ -107,7 +115,7 @@ func (s *SourceService) ValidateFilename(filename string) error {
 
 	ext := strings.ToLower(filepath.Ext(filename))
 	if !ValidExtensions[ext] {
-		return fmt.Errorf("only .geojson, .json, .parquet, or .geoparquet files are allowed")
+		return fmt.Errorf("only .geojson, .json, .geojsonl, .geojsonseq, .parquet, .geoparquet, or .fgb files are allowed")
 	}
 
 	return nil
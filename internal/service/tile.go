@@ -1,20 +1,43 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+)
+
+// Errors returned by TileService, for callers to tell bad requests from
+// failures.
+var (
+	ErrInvalidTileName = errors.New("invalid tileset name")
+	ErrInvalidArchive  = errors.New("invalid PMTiles archive")
+	ErrTileNotFound    = errors.New("tileset not found")
+	ErrVersionNotFound = errors.New("tileset version not found")
+	ErrTileExists      = errors.New("tileset already exists")
+	ErrTileInUse       = errors.New("tileset is in use")
 )
 
-// TileService manages PMTiles files.
+// TileService manages PMTiles files and their versions. Layers pinned to
+// a version keep it from being pruned.
 type TileService struct {
 	tilesDir string
+	layers   *LayerService
+	mu       sync.Mutex // guards version manifests
+	cache    archiveCache
 }
 
 // NewTileService creates a new tile service.
-func NewTileService(dataDir string) *TileService {
+func NewTileService(dataDir string, layers *LayerService) *TileService {
 	return &TileService{
 		tilesDir: filepath.Join(dataDir, "tiles"),
+		layers:   layers,
 	}
 }
 
@@ -33,7 +56,8 @@ func (s *TileService) List() ([]TileFile, error) {
 		if entry.IsDir() {
 			continue
 		}
-		if filepath.Ext(entry.Name()) != ".pmtiles" {
+		// Hidden files are archives still being generated
+		if filepath.Ext(entry.Name()) != ".pmtiles" || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -42,15 +66,258 @@ func (s *TileService) List() ([]TileFile, error) {
 			continue
 		}
 
-		files = append(files, TileFile{
-			Name: entry.Name(),
-			Size: formatSize(info.Size()),
-		})
+		files = append(files, s.describe(entry.Name(), info))
 	}
 
 	return files, nil
 }
 
+// Get returns a PMTiles file by name.
+func (s *TileService) Get(name string) (TileFile, error) {
+	if err := validTileName(name); err != nil {
+		return TileFile{}, err
+	}
+	info, err := os.Stat(filepath.Join(s.tilesDir, name))
+	if err != nil || info.IsDir() {
+		return TileFile{}, fmt.Errorf("tileset %q not found", name)
+	}
+	return s.describe(name, info), nil
+}
+
+// describe reads a PMTiles file's header and metadata into a TileFile.
+func (s *TileService) describe(name string, info os.FileInfo) TileFile {
+	f := TileFile{
+		Name:    name,
+		Size:    pmtiles.FormatSize(info.Size()),
+		Bytes:   info.Size(),
+		ModTime: info.ModTime().UTC(),
+	}
+	r, release, err := s.Archive(name)
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	defer release()
+
+	h := r.Header()
+	f.TileType = h.TileType.String()
+	f.Compression = h.TileCompression.String()
+	f.MinZoom = int(h.MinZoom)
+	f.MaxZoom = int(h.MaxZoom)
+	f.Bounds = []float64{pmtiles.FromE7(h.MinLonE7), pmtiles.FromE7(h.MinLatE7), pmtiles.FromE7(h.MaxLonE7), pmtiles.FromE7(h.MaxLatE7)}
+	f.Center = []float64{pmtiles.FromE7(h.CenterLonE7), pmtiles.FromE7(h.CenterLatE7), float64(h.CenterZoom)}
+	f.AddressedTiles = h.AddressedTilesCount
+	f.TileEntries = h.TileEntriesCount
+	f.TileContents = h.TileContentsCount
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	f.Layers = tileLayers(metadata)
+	return f
+}
+
+// tileLayers lists the vector layers in PMTiles metadata, with geometry
+// types taken from the tilestats tippecanoe and gotiler write.
+func tileLayers(metadata map[string]any) []TileLayer {
+	geomTypes := make(map[string]string)
+	tilestats, _ := metadata["tilestats"].(map[string]any)
+	stats, _ := tilestats["layers"].([]any)
+	for _, entry := range stats {
+		m, _ := entry.(map[string]any)
+		switch metadataString(m, "geometry", "") {
+		case "Point":
+			geomTypes[metadataString(m, "layer", "")] = "point"
+		case "LineString":
+			geomTypes[metadataString(m, "layer", "")] = "line"
+		case "Polygon":
+			geomTypes[metadataString(m, "layer", "")] = "polygon"
+		}
+	}
+
+	var layers []TileLayer
+	for _, vl := range vectorLayers(metadata, 0, 0) {
+		layers = append(layers, TileLayer{Name: vl.ID, GeomType: geomTypes[vl.ID]})
+	}
+	return layers
+}
+
+// Layers returns the IDs of layers that use a PMTiles file.
+func (s *TileService) Layers(name string) []string {
+	if s.layers == nil {
+		return nil
+	}
+	var ids []string
+	for id, layer := range s.layers.List() {
+		if layer.File == name {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Save validates an uploaded PMTiles archive and publishes it as the
+// current version of tileset name.
+func (s *TileService) Save(name string, content io.Reader) (TileFile, error) {
+	if err := validTileName(name); err != nil {
+		return TileFile{}, err
+	}
+	if err := os.MkdirAll(s.tilesDir, 0755); err != nil {
+		return TileFile{}, fmt.Errorf("failed to create tiles directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.tilesDir, "."+strings.TrimSuffix(name, ".pmtiles")+"-*.pmtiles")
+	if err != nil {
+		return TileFile{}, err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+	tmp.Chmod(0644)
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return TileFile{}, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return TileFile{}, err
+	}
+	if err := validateArchive(tmp.Name()); err != nil {
+		return TileFile{}, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if _, err := s.Commit(tmp.Name(), name, TileVersion{}); err != nil {
+		return TileFile{}, err
+	}
+	return s.Get(name)
+}
+
+// Delete removes a PMTiles file and its versions. Files used by a layer
+// are only removed when force is set.
+func (s *TileService) Delete(name string, force bool) error {
+	if err := validTileName(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(s.tilesDir, name)); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrTileNotFound, name)
+	}
+	if layers := s.Layers(name); len(layers) > 0 && !force {
+		return fmt.Errorf("%w: layers %s use %s", ErrTileInUse, strings.Join(layers, ", "), name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(filepath.Join(s.tilesDir, name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrTileNotFound, name)
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	s.cache.evict(name)
+	if err := os.RemoveAll(s.versionDir(name)); err != nil {
+		return fmt.Errorf("failed to delete versions: %w", err)
+	}
+
+	DefaultBus.Publish(Event{Resource: "tiles", Action: "deleted", ID: name})
+	return nil
+}
+
+// Rename moves a PMTiles file and its versions to a new name, updating
+// the layers that use it. It fails if the new name is taken.
+func (s *TileService) Rename(name, newName string) (TileFile, error) {
+	if err := validTileName(name); err != nil {
+		return TileFile{}, err
+	}
+	if err := validTileName(newName); err != nil {
+		return TileFile{}, err
+	}
+
+	if err := s.move(name, newName); err != nil {
+		return TileFile{}, err
+	}
+
+	for _, id := range s.Layers(name) {
+		layer, ok := s.layers.Get(id)
+		if !ok {
+			continue
+		}
+		layer.File = newName
+		if _, err := s.layers.Update(id, layer); err != nil {
+			return TileFile{}, fmt.Errorf("updating layer %s: %w", id, err)
+		}
+	}
+
+	DefaultBus.Publish(Event{Resource: "tiles", Action: "deleted", ID: name})
+	DefaultBus.Publish(Event{Resource: "tiles", Action: "created", ID: newName})
+	return s.Get(newName)
+}
+
+// move renames a PMTiles file and its versions directory. Every write
+// to the tiles directory holds s.mu, so nothing can take the new name
+// between the checks and the renames.
+func (s *TileService) move(name, newName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldPath, newPath := filepath.Join(s.tilesDir, name), filepath.Join(s.tilesDir, newName)
+	if _, err := os.Stat(oldPath); err != nil {
+		return fmt.Errorf("%w: %s", ErrTileNotFound, name)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("%w: %s", ErrTileExists, newName)
+	}
+	if _, err := os.Stat(s.versionDir(newName)); err == nil {
+		return fmt.Errorf("%w: versions of %s are still kept", ErrTileExists, newName)
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+	if err := os.Rename(s.versionDir(name), s.versionDir(newName)); err != nil && !os.IsNotExist(err) {
+		// Put the file back so it stays with its versions
+		if undo := os.Rename(newPath, oldPath); undo != nil {
+			return fmt.Errorf("failed to move versions: %w (and restoring %s failed: %v)", err, name, undo)
+		}
+		return fmt.Errorf("failed to move versions: %w", err)
+	}
+	s.cache.evict(name)
+	return nil
+}
+
+// Verify checks every directory entry and tile of a tileset, along with
+// its header counters. Problems with the archive are reported in the
+// result, not as an error.
+func (s *TileService) Verify(name string) (TileVerification, error) {
+	if err := validTileName(name); err != nil {
+		return TileVerification{}, err
+	}
+	f, err := os.Open(filepath.Join(s.tilesDir, name))
+	if os.IsNotExist(err) {
+		return TileVerification{}, fmt.Errorf("%w: %s", ErrTileNotFound, name)
+	}
+	if err != nil {
+		return TileVerification{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return TileVerification{}, err
+	}
+
+	res, err := pmtiles.Verify(f, info.Size())
+	if err != nil {
+		// Not a readable archive at all
+		res.Problems = []string{err.Error()}
+	}
+	return TileVerification{
+		Name:     name,
+		Valid:    len(res.Problems) == 0,
+		Entries:  res.Entries,
+		Tiles:    res.Tiles,
+		Problems: append([]string{}, res.Problems...),
+	}, nil
+}
+
 // ListPaged returns a page of tile files with total count.
 func (s *TileService) ListPaged(offset, limit int) ([]TileFile, int, error) {
 	all, err := s.List()
@@ -72,17 +339,3 @@ func (s *TileService) ListPaged(offset, limit int) ([]TileFile, int, error) {
 func (s *TileService) TilesDir() string {
 	return s.tilesDir
 }
-
-// formatSize returns a human-readable file size.
-func formatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+)
+
+// TileJSON is a TileJSON 3.0 description of a tileset.
+type TileJSON struct {
+	TileJSON     string        `json:"tilejson" doc:"TileJSON spec version" example:"3.0.0"`
+	Name         string        `json:"name,omitempty" doc:"Tileset name" example:"buildings"`
+	Description  string        `json:"description,omitempty" doc:"Tileset description"`
+	Version      string        `json:"version,omitempty" doc:"Tileset version" example:"2"`
+	Attribution  string        `json:"attribution,omitempty" doc:"Attribution to display on the map"`
+	Scheme       string        `json:"scheme" doc:"Tile coordinate scheme" example:"xyz"`
+	Tiles        []string      `json:"tiles" doc:"Tile URL templates"`
+	MinZoom      int           `json:"minzoom" doc:"Minimum zoom level" example:"0"`
+	MaxZoom      int           `json:"maxzoom" doc:"Maximum zoom level" example:"14"`
+	Bounds       []float64     `json:"bounds" doc:"Bounds as west, south, east, north" example:"[-180,-85.0511,180,85.0511]"`
+	Center       []float64     `json:"center" doc:"Default view as longitude, latitude, zoom" example:"[0,0,2]"`
+	VectorLayers []VectorLayer `json:"vector_layers,omitempty" doc:"Vector layers in the tiles"`
+}
+
+// VectorLayer describes one layer of a vector tileset.
+type VectorLayer struct {
+	ID          string            `json:"id" doc:"Layer name" example:"buildings"`
+	Fields      map[string]string `json:"fields" doc:"Attribute names and types"`
+	Description string            `json:"description,omitempty" doc:"Layer description"`
+	MinZoom     int               `json:"minzoom" doc:"Minimum zoom level of the layer"`
+	MaxZoom     int               `json:"maxzoom" doc:"Maximum zoom level of the layer"`
+}
+
+// TileJSON describes tileset name from its PMTiles header and metadata.
+// baseURL is the scheme and host tile URLs are served from.
+func (s *TileService) TileJSON(name, baseURL string) (TileJSON, error) {
+	if err := validTileName(name); err != nil {
+		return TileJSON{}, err
+	}
+	r, release, err := s.Archive(name)
+	if err != nil {
+		return TileJSON{}, err
+	}
+	defer release()
+	metadata, err := r.Metadata()
+	if err != nil {
+		return TileJSON{}, err
+	}
+
+	h := r.Header()
+	stem := strings.TrimSuffix(name, ".pmtiles")
+	tj := TileJSON{
+		TileJSON:    "3.0.0",
+		Name:        metadataString(metadata, "name", stem),
+		Description: metadataString(metadata, "description", ""),
+		Version:     metadataString(metadata, "version", ""),
+		Attribution: metadataString(metadata, "attribution", ""),
+		Scheme:      "xyz",
+		Tiles:       []string{fmt.Sprintf("%s/tiles/%s/{z}/{x}/{y}.%s", strings.TrimSuffix(baseURL, "/"), stem, h.TileType)},
+		MinZoom:     int(h.MinZoom),
+		MaxZoom:     int(h.MaxZoom),
+		Bounds:      []float64{pmtiles.FromE7(h.MinLonE7), pmtiles.FromE7(h.MinLatE7), pmtiles.FromE7(h.MaxLonE7), pmtiles.FromE7(h.MaxLatE7)},
+		Center:      []float64{pmtiles.FromE7(h.CenterLonE7), pmtiles.FromE7(h.CenterLatE7), float64(h.CenterZoom)},
+	}
+	if h.MinLonE7 == h.MaxLonE7 && h.MinLatE7 == h.MaxLatE7 {
+		tj.Bounds = []float64{-180, -85.05112878, 180, 85.05112878}
+	}
+
+	if h.TileType == pmtiles.Mvt {
+		tj.VectorLayers = vectorLayers(metadata, tj.MinZoom, tj.MaxZoom)
+	}
+	return tj, nil
+}
+
+// vectorLayers reads the vector_layers of PMTiles metadata, defaulting
+// zoom ranges missing from an entry to the archive's.
+func vectorLayers(metadata map[string]any, minZoom, maxZoom int) []VectorLayer {
+	raw, _ := metadata["vector_layers"].([]any)
+	layers := make([]VectorLayer, 0, len(raw))
+	for _, entry := range raw {
+		m, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		layer := VectorLayer{
+			ID:          metadataString(m, "id", ""),
+			Fields:      make(map[string]string),
+			Description: metadataString(m, "description", ""),
+			MinZoom:     minZoom,
+			MaxZoom:     maxZoom,
+		}
+		if layer.ID == "" {
+			continue
+		}
+		if z, ok := m["minzoom"].(float64); ok {
+			layer.MinZoom = int(z)
+		}
+		if z, ok := m["maxzoom"].(float64); ok {
+			layer.MaxZoom = int(z)
+		}
+		fields, _ := m["fields"].(map[string]any)
+		for k, v := range fields {
+			layer.Fields[k], _ = v.(string)
+		}
+		layers = append(layers, layer)
+	}
+	return layers
+}
+
+// metadataString returns a string metadata value, or def when it is
+// missing or not a string.
+func metadataString(metadata map[string]any, key, def string) string {
+	if v, ok := metadata[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
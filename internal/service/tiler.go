@@ -1,28 +1,114 @@
 package service
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+	"github.com/joeblew999/plat-geo/internal/tiler"
+	"github.com/joeblew999/plat-geo/internal/tiler/gotiler"
 )
 
-// TilerService handles tile generation using Tippecanoe.
+// TilerService handles tile generation with a registry of tiler engines:
+// tippecanoe when it is installed, and the built-in go engine otherwise.
 type TilerService struct {
 	sourcesDir string
 	tilesDir   string
+	tiles      *TileService
+	engines    []tiler.Tiler // in order of preference for "auto"
 }
 
-// NewTilerService creates a new tiler service.
-func NewTilerService(dataDir string) *TilerService {
+// NewTilerService creates a new tiler service that publishes generated
+// tilesets as new versions through tiles.
+func NewTilerService(dataDir string, tiles *TileService) *TilerService {
 	return &TilerService{
 		sourcesDir: filepath.Join(dataDir, "sources"),
 		tilesDir:   filepath.Join(dataDir, "tiles"),
+		tiles:      tiles,
+		engines:    []tiler.Tiler{tiler.NewTippecanoe(), gotiler.New()},
+	}
+}
+
+// Tiler engine names accepted by the engine option.
+const (
+	EngineAuto       = "auto"
+	EngineTippecanoe = "tippecanoe"
+	EngineGo         = "go"
+)
+
+// TilerEngine describes a registered tiler engine.
+type TilerEngine struct {
+	Name      string `json:"name" doc:"Engine name" example:"tippecanoe"`
+	Available bool   `json:"available" doc:"Whether the engine can be used"`
+	Default   bool   `json:"default" doc:"Whether auto selects this engine when settings allow"`
+}
+
+// Engines lists the registered engines and their availability.
+func (s *TilerService) Engines() []TilerEngine {
+	out := make([]TilerEngine, len(s.engines))
+	def := ""
+	for i, t := range s.engines {
+		out[i] = TilerEngine{Name: t.Name(), Available: t.Available()}
+		if def == "" && out[i].Available {
+			def = t.Name()
+			out[i].Default = true
+		}
+	}
+	return out
+}
+
+// engine returns the registered engine with the given name.
+func (s *TilerService) engine(name string) (tiler.Tiler, error) {
+	for _, t := range s.engines {
+		if t.Name() == name {
+			if !t.Available() {
+				if name == EngineTippecanoe {
+					return nil, fmt.Errorf("tippecanoe is not installed. Run 'task tippecanoe:install' to install it, or use the go engine")
+				}
+				return nil, fmt.Errorf("tiler engine %q is not available", name)
+			}
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown tiler engine %q (use auto, tippecanoe or go)", name)
+}
+
+// selectEngine resolves an engine option. Auto picks the first available
+// engine that supports every source and layer config, so sources or
+// settings tippecanoe lacks fall through to the go engine.
+func (s *TilerService) selectEngine(name string, sources []string, configs []tiler.TileConfig) (tiler.Tiler, error) {
+	if name != "" && name != EngineAuto {
+		return s.engine(name)
+	}
+	for _, t := range s.engines {
+		if t.Available() && supports(t, sources, configs) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("no available tiler engine supports these settings")
+}
+
+// supports reports whether an engine can tile the sources with configs.
+func supports(t tiler.Tiler, sources []string, configs []tiler.TileConfig) bool {
+	tip, ok := t.(*tiler.Tippecanoe)
+	if !ok {
+		return true
+	}
+	for _, src := range sources {
+		if nativeOnly(src) {
+			return false
+		}
 	}
+	for _, config := range configs {
+		if _, err := tip.Args("in", "out", config); err != nil {
+			return false
+		}
+	}
+	return true
 }
 
 // TileGenerateOptions contains options for tile generation.
@@ -32,12 +118,54 @@ type TileGenerateOptions struct {
 	LayerName  string `json:"layerName" doc:"Layer name in tiles"`
 	MinZoom    int    `json:"minZoom" minimum:"0" maximum:"22" doc:"Minimum zoom level"`
 	MaxZoom    int    `json:"maxZoom" minimum:"0" maximum:"22" doc:"Maximum zoom level"`
+	Engine     string `json:"engine,omitempty" enum:"auto,tippecanoe,go" default:"auto" doc:"Tiler engine; auto uses tippecanoe when installed and able, else go"`
+
+	Include          []string          `json:"include,omitempty" doc:"Only keep these properties in tiles"`
+	Exclude          []string          `json:"exclude,omitempty" doc:"Drop these properties from tiles"`
+	AttributeTypes   map[string]string `json:"attributeTypes,omitempty" doc:"Coerce properties to string, float, int or bool"`
+	AttributeMinZoom map[string]int    `json:"attributeMinZoom,omitempty" doc:"Strip properties from tiles below these zooms (go engine only)"`
+}
+
+// TileConfig converts the options to a tiler config.
+func (o TileGenerateOptions) TileConfig() tiler.TileConfig {
+	return tiler.TileConfig{
+		MinZoom:          o.MinZoom,
+		MaxZoom:          o.MaxZoom,
+		Layer:            o.LayerName,
+		DropDensest:      true,
+		Include:          o.Include,
+		Exclude:          o.Exclude,
+		AttributeTypes:   o.AttributeTypes,
+		AttributeMinZoom: o.AttributeMinZoom,
+	}
+}
+
+// TileLayerOptions describes one layer of a multi-layer tileset.
+type TileLayerOptions struct {
+	SourceFile string `json:"sourceFile" required:"true" doc:"Source file name"`
+	LayerName  string `json:"layerName" required:"true" doc:"Layer name in tiles"`
+	MinZoom    int    `json:"minZoom" minimum:"0" maximum:"22" doc:"Minimum zoom level"`
+	MaxZoom    int    `json:"maxZoom" minimum:"0" maximum:"22" doc:"Maximum zoom level"`
+
+	Include          []string          `json:"include,omitempty" doc:"Only keep these properties in tiles"`
+	Exclude          []string          `json:"exclude,omitempty" doc:"Drop these properties from tiles"`
+	AttributeTypes   map[string]string `json:"attributeTypes,omitempty" doc:"Coerce properties to string, float, int or bool"`
+	AttributeMinZoom map[string]int    `json:"attributeMinZoom,omitempty" doc:"Strip properties from tiles below these zooms (go engine only)"`
+}
+
+// TileLayersOptions contains options for generating one tileset from
+// several sources, each as its own vector layer.
+type TileLayersOptions struct {
+	OutputName string             `json:"outputName" required:"true" doc:"Output PMTiles name"`
+	Engine     string             `json:"engine,omitempty" enum:"auto,tippecanoe,go" default:"auto" doc:"Tiler engine; auto uses tippecanoe when installed and able, else go"`
+	Layers     []TileLayerOptions `json:"layers" required:"true" minItems:"1" doc:"Layers to include, one per source file"`
 }
 
 // ProgressFunc is called with progress updates during tile generation.
 type ProgressFunc func(progress int, status string)
 
-// Generate creates PMTiles from a source file using Tippecanoe.
+// Generate creates PMTiles from a source file with the engine chosen by
+// opts.Engine.
 func (s *TilerService) Generate(ctx context.Context, opts TileGenerateOptions, onProgress ProgressFunc) error {
 	// Apply defaults
 	if opts.LayerName == "" {
@@ -47,77 +175,114 @@ func (s *TilerService) Generate(ctx context.Context, opts TileGenerateOptions, o
 		opts.MaxZoom = 14
 	}
 
-	// Ensure output has .pmtiles extension
-	if !strings.HasSuffix(opts.OutputName, ".pmtiles") {
-		opts.OutputName = opts.OutputName + ".pmtiles"
+	opts, err := s.checkGenerate(opts)
+	if err != nil {
+		return err
 	}
-
 	sourcePath := filepath.Join(s.sourcesDir, opts.SourceFile)
-	outputPath := filepath.Join(s.tilesDir, opts.OutputName)
-
-	// Validate source exists
-	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		return fmt.Errorf("source file not found: %s", opts.SourceFile)
-	}
 
 	// Ensure tiles directory exists
 	if err := os.MkdirAll(s.tilesDir, 0755); err != nil {
 		return fmt.Errorf("failed to create tiles directory: %w", err)
 	}
 
-	if onProgress != nil {
-		onProgress(10, "Starting tile generation...")
+	config := opts.TileConfig()
+	t, err := s.selectEngine(opts.Engine, []string{opts.SourceFile}, []tiler.TileConfig{config})
+	if err != nil {
+		return err
 	}
 
-	args := []string{
-		"-o", outputPath,
-		"-l", opts.LayerName,
-		"-Z", strconv.Itoa(opts.MinZoom),
-		"-z", strconv.Itoa(opts.MaxZoom),
-		"--force",
-		"--drop-densest-as-needed",
-		sourcePath,
+	if onProgress != nil {
+		onProgress(10, fmt.Sprintf("Tiling with the %s engine...", t.Name()))
+	}
+	version := TileVersion{Sources: []string{opts.SourceFile}, Engine: t.Name()}
+	version.Options, _ = json.Marshal(opts)
+	err = s.writeArchive(opts.OutputName, version, func(tmpPath string) error {
+		return t.Tile(ctx, sourcePath, tmpPath, config, engineProgress(onProgress))
+	})
+	if err != nil {
+		return fmt.Errorf("tile generation failed: %w", err)
 	}
 
 	if onProgress != nil {
-		onProgress(30, "Running Tippecanoe...")
+		onProgress(100, "Tiles generated successfully!")
 	}
 
-	cmd := exec.CommandContext(ctx, "tippecanoe", args...)
+	return nil
+}
+
+// engineProgress adapts an engine's progress updates to a ProgressFunc,
+// scaling them to 10-95% of the overall progress.
+func engineProgress(onProgress ProgressFunc) tiler.ProgressFunc {
+	if onProgress == nil {
+		return nil
+	}
+	return func(p tiler.Progress) {
+		status := fmt.Sprintf("Processing: %.1f%%", p.Percent)
+		if p.Zoom >= 0 {
+			status = fmt.Sprintf("Processing zoom %d: %.1f%%", p.Zoom, p.Percent)
+		}
+		if p.TilesDone > 0 {
+			status += fmt.Sprintf(" (%d tiles, %s)", p.TilesDone, pmtiles.FormatSize(p.BytesWritten))
+		}
+		onProgress(10+int(p.Percent*0.85), status)
+	}
+}
 
-	// Capture stderr for progress/errors
-	stderr, err := cmd.StderrPipe()
+// GenerateLayers creates one PMTiles archive with a vector layer per
+// source file, each with its own zoom range and attribute settings.
+func (s *TilerService) GenerateLayers(ctx context.Context, opts TileLayersOptions, onProgress ProgressFunc) error {
+	opts, err := s.checkLayers(opts)
 	if err != nil {
-		return fmt.Errorf("failed to get stderr pipe: %w", err)
+		return err
 	}
 
-	if err := cmd.Start(); err != nil {
-		if strings.Contains(err.Error(), "executable file not found") {
-			return fmt.Errorf("tippecanoe is not installed. Run 'task tippecanoe:install' to install it")
+	inputs := make([]tiler.LayerInput, len(opts.Layers))
+	sources := make([]string, len(opts.Layers))
+	configs := make([]tiler.TileConfig, len(opts.Layers))
+	for i, layer := range opts.Layers {
+		if layer.MinZoom == 0 && layer.MaxZoom == 0 {
+			layer.MaxZoom = 14
 		}
-		return fmt.Errorf("failed to start tippecanoe: %w", err)
-	}
-
-	// Read stderr for progress updates
-	scanner := bufio.NewScanner(stderr)
-	for scanner.Scan() {
-		line := scanner.Text()
-		// Tippecanoe outputs progress like "99.9%  11/14"
-		if strings.Contains(line, "%") && onProgress != nil {
-			// Extract percentage if possible
-			parts := strings.Fields(line)
-			if len(parts) > 0 {
-				pctStr := strings.TrimSuffix(parts[0], "%")
-				if pct, err := strconv.ParseFloat(pctStr, 64); err == nil {
-					// Scale 0-100% to 30-90% of our progress
-					progress := 30 + int(pct*0.6)
-					onProgress(progress, fmt.Sprintf("Processing: %s%%", pctStr))
-				}
-			}
+		inputs[i] = tiler.LayerInput{
+			Path: filepath.Join(s.sourcesDir, layer.SourceFile),
+			Config: tiler.TileConfig{
+				MinZoom:          layer.MinZoom,
+				MaxZoom:          layer.MaxZoom,
+				Layer:            layer.LayerName,
+				Include:          layer.Include,
+				Exclude:          layer.Exclude,
+				AttributeTypes:   layer.AttributeTypes,
+				AttributeMinZoom: layer.AttributeMinZoom,
+			},
 		}
+		sources[i] = layer.SourceFile
+		configs[i] = inputs[i].Config
+	}
+
+	t, err := s.selectEngine(opts.Engine, sources, configs)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.tilesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create tiles directory: %w", err)
+	}
+
+	if onProgress != nil {
+		onProgress(10, fmt.Sprintf("Tiling %d layers with the %s engine...", len(inputs), t.Name()))
 	}
 
-	if err := cmd.Wait(); err != nil {
+	config := tiler.TileConfig{
+		Layer:       strings.TrimSuffix(opts.OutputName, ".pmtiles"),
+		DropDensest: true,
+	}
+	version := TileVersion{Sources: sources, Engine: t.Name()}
+	version.Options, _ = json.Marshal(opts)
+	err = s.writeArchive(opts.OutputName, version, func(tmpPath string) error {
+		return t.TileLayers(ctx, inputs, tmpPath, config, engineProgress(onProgress))
+	})
+	if err != nil {
 		return fmt.Errorf("tile generation failed: %w", err)
 	}
 
@@ -128,6 +293,126 @@ func (s *TilerService) Generate(ctx context.Context, opts TileGenerateOptions, o
 	return nil
 }
 
+// checkGenerate validates the source and output names of opts, returning
+// it with the output name's .pmtiles extension added.
+func (s *TilerService) checkGenerate(opts TileGenerateOptions) (TileGenerateOptions, error) {
+	if err := s.ValidateSourceFile(opts.SourceFile); err != nil {
+		return opts, err
+	}
+	name, err := archiveName(opts.OutputName)
+	if err != nil {
+		return opts, err
+	}
+	opts.OutputName = name
+	return opts, nil
+}
+
+// checkLayers validates the source and output names of opts, returning
+// it with the output name's .pmtiles extension added.
+func (s *TilerService) checkLayers(opts TileLayersOptions) (TileLayersOptions, error) {
+	if len(opts.Layers) == 0 {
+		return opts, fmt.Errorf("at least one layer is required")
+	}
+	for _, layer := range opts.Layers {
+		if err := s.ValidateSourceFile(layer.SourceFile); err != nil {
+			return opts, fmt.Errorf("layer %q: %w", layer.LayerName, err)
+		}
+	}
+	name, err := archiveName(opts.OutputName)
+	if err != nil {
+		return opts, err
+	}
+	opts.OutputName = name
+	return opts, nil
+}
+
+// archiveName returns the tileset name for an output name, adding the
+// .pmtiles extension when it is missing.
+func archiveName(name string) (string, error) {
+	if !strings.HasSuffix(name, ".pmtiles") {
+		name += ".pmtiles"
+	}
+	if err := validTileName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// writeArchive has generate write an archive to a hidden temp file in the
+// tiles directory, validates it and commits it as the new version of
+// tileset name, so readers never see a partial archive and a failed run
+// leaves the previous one intact.
+func (s *TilerService) writeArchive(name string, version TileVersion, generate func(tmpPath string) error) error {
+	// Keep the .pmtiles extension: tippecanoe picks its output format by it
+	tmp, err := os.CreateTemp(s.tilesDir, "."+strings.TrimSuffix(name, ".pmtiles")+"-*.pmtiles")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Chmod(0644)
+	tmp.Close()
+	defer os.Remove(tmpPath) // fails harmlessly once renamed
+
+	if err := generate(tmpPath); err != nil {
+		return err
+	}
+	if err := validateArchive(tmpPath); err != nil {
+		return fmt.Errorf("invalid output: %w", err)
+	}
+	if s.tiles == nil {
+		return os.Rename(tmpPath, filepath.Join(s.tilesDir, name))
+	}
+	_, err = s.tiles.Commit(tmpPath, name, version)
+	return err
+}
+
+// validateArchive checks that a PMTiles archive is complete: its header,
+// root directory and metadata decode, every section lies within the file,
+// and it has tiles.
+func validateArchive(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	h := r.Header()
+	size := uint64(info.Size())
+	for _, section := range []struct {
+		name           string
+		offset, length uint64
+	}{
+		{"root directory", h.RootOffset, h.RootLength},
+		{"metadata", h.MetadataOffset, h.MetadataLength},
+		{"leaf directories", h.LeafDirectoryOffset, h.LeafDirectoryLength},
+		{"tile data", h.TileDataOffset, h.TileDataLength},
+	} {
+		if section.offset+section.length > size {
+			return fmt.Errorf("%s extends past the end of the file", section.name)
+		}
+	}
+	if _, err := r.Metadata(); err != nil {
+		return err
+	}
+	if h.AddressedTilesCount == 0 {
+		return fmt.Errorf("archive has no tiles")
+	}
+	return nil
+}
+
+// nativeOnly reports whether a source can only be tiled by the go engine.
+func nativeOnly(sourceFile string) bool {
+	switch strings.ToLower(filepath.Ext(sourceFile)) {
+	case ".parquet", ".geoparquet":
+		return true
+	}
+	return false
+}
+
 // SourcesDir returns the sources directory path.
 func (s *TilerService) SourcesDir() string {
 	return s.sourcesDir
@@ -151,6 +436,9 @@ func (s *TilerService) ValidateSourceFile(filename string) error {
 		".json":       true,
 		".parquet":    true,
 		".geoparquet": true,
+		".geojsonl":   true,
+		".geojsonseq": true,
+		".fgb":        true,
 	}
 	if !validExts[ext] {
 		return fmt.Errorf("unsupported file type: %s", ext)
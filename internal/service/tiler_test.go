@@ -0,0 +1,88 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/plat-geo/internal/tiler"
+)
+
+// setTippecanoeInstalled points PATH at a directory holding a stub
+// tippecanoe executable, or at an empty one.
+func setTippecanoeInstalled(t *testing.T, installed bool) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub tippecanoe is a shell script")
+	}
+	bin := t.TempDir()
+	if installed {
+		if err := os.WriteFile(filepath.Join(bin, "tippecanoe"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestTilerEngines(t *testing.T) {
+	for _, installed := range []bool{true, false} {
+		setTippecanoeInstalled(t, installed)
+		s := NewTilerService(t.TempDir(), nil)
+
+		engines := s.Engines()
+		want := []TilerEngine{
+			{Name: EngineTippecanoe, Available: installed, Default: installed},
+			{Name: EngineGo, Available: true, Default: !installed},
+		}
+		if len(engines) != len(want) {
+			t.Fatalf("installed %v: engines = %+v, want %+v", installed, engines, want)
+		}
+		for i := range want {
+			if engines[i] != want[i] {
+				t.Errorf("installed %v: engine %d = %+v, want %+v", installed, i, engines[i], want[i])
+			}
+		}
+	}
+}
+
+func TestTilerSelectEngine(t *testing.T) {
+	perZoom := tiler.TileConfig{MaxZoom: 10, AttributeMinZoom: map[string]int{"name": 8}}
+	tests := []struct {
+		desc      string
+		installed bool
+		engine    string
+		sources   []string
+		configs   []tiler.TileConfig
+		want      string // engine name, or an error substring
+		wantErr   bool
+	}{
+		{"auto prefers tippecanoe", true, EngineAuto, []string{"roads.geojson"}, nil, EngineTippecanoe, false},
+		{"empty means auto", true, "", []string{"roads.geojson"}, nil, EngineTippecanoe, false},
+		{"auto without tippecanoe", false, EngineAuto, []string{"roads.geojson"}, nil, EngineGo, false},
+		{"auto with parquet", true, EngineAuto, []string{"roads.geojson", "pois.parquet"}, nil, EngineGo, false},
+		{"auto with geoparquet", true, EngineAuto, []string{"pois.geoparquet"}, nil, EngineGo, false},
+		{"auto with go-only settings", true, EngineAuto, []string{"roads.geojson"}, []tiler.TileConfig{{MaxZoom: 10}, perZoom}, EngineGo, false},
+		{"tippecanoe", true, EngineTippecanoe, []string{"roads.geojson"}, nil, EngineTippecanoe, false},
+		{"go", true, EngineGo, []string{"roads.geojson"}, nil, EngineGo, false},
+		{"tippecanoe missing", false, EngineTippecanoe, []string{"roads.geojson"}, nil, "tippecanoe is not installed", true},
+		{"unknown engine", true, "mapnik", []string{"roads.geojson"}, nil, `unknown tiler engine "mapnik"`, true},
+	}
+	for _, tt := range tests {
+		setTippecanoeInstalled(t, tt.installed)
+		s := NewTilerService(t.TempDir(), nil)
+
+		got, err := s.selectEngine(tt.engine, tt.sources, tt.configs)
+		switch {
+		case tt.wantErr:
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%s: engine %v, err %v; want an error containing %q", tt.desc, got, err, tt.want)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", tt.desc, err)
+		case got.Name() != tt.want:
+			t.Errorf("%s: selected %s, want %s", tt.desc, got.Name(), tt.want)
+		}
+	}
+}
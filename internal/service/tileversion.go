@@ -0,0 +1,342 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+)
+
+// maxTileVersions bounds the prior versions kept per tileset, besides the
+// current one. Versions pinned by a layer are never pruned.
+const maxTileVersions = 5
+
+// versionsDir is the tiles subdirectory holding prior versions, served
+// alongside the tilesets so pinned layers can load them.
+const versionsDir = "versions"
+
+// TileVersion describes one generated version of a tileset.
+type TileVersion struct {
+	ID        string          `json:"id" doc:"Content hash of the archive" example:"3f9a1c2b7d4e"`
+	Size      string          `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	Sources   []string        `json:"sources,omitempty" doc:"Source files the version was generated from" example:"[\"buildings.geojson\"]"`
+	Engine    string          `json:"engine,omitempty" doc:"Tiler engine that generated the version" example:"go"`
+	Options   json.RawMessage `json:"options,omitempty" doc:"Generation options"`
+	CreatedAt time.Time       `json:"createdAt" doc:"When the version was generated"`
+	Current   bool            `json:"current" doc:"Whether the version is the one being served"`
+	PinnedBy  []string        `json:"pinnedBy,omitempty" doc:"IDs of layers pinned to this version"`
+	URL       string          `json:"url" doc:"URL of the version's archive" example:"/tiles/versions/buildings/3f9a1c2b7d4e.pmtiles"`
+}
+
+// tileManifest records the versions of a tileset, newest first.
+type tileManifest struct {
+	Current  string        `json:"current"`
+	Versions []TileVersion `json:"versions"`
+}
+
+// VersionPath returns the archive path of a tileset version.
+func (s *TileService) VersionPath(name, id string) string {
+	return filepath.Join(s.versionDir(name), id+".pmtiles")
+}
+
+// VersionURL returns the URL a tileset version is served at.
+func VersionURL(name, id string) string {
+	return "/tiles/" + versionsDir + "/" + strings.TrimSuffix(name, ".pmtiles") + "/" + id + ".pmtiles"
+}
+
+// versionDir returns the directory holding a tileset's versions.
+func (s *TileService) versionDir(name string) string {
+	return filepath.Join(s.tilesDir, versionsDir, strings.TrimSuffix(name, ".pmtiles"))
+}
+
+// Versions returns the recorded versions of a tileset, newest first.
+func (s *TileService) Versions(name string) ([]TileVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := validTileName(name); err != nil {
+		return nil, err
+	}
+	m, err := s.loadManifest(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Versions) == 0 {
+		if _, err := os.Stat(filepath.Join(s.tilesDir, name)); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrTileNotFound, name)
+		}
+	}
+	return s.annotate(name, m), nil
+}
+
+// VersionsPaged returns a page of a tileset's versions, newest first, and
+// the total number of versions.
+func (s *TileService) VersionsPaged(name string, offset, limit int) ([]TileVersion, int, error) {
+	all, err := s.Versions(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(all)
+	if offset >= total {
+		return []TileVersion{}, total, nil
+	}
+	return all[offset:min(offset+limit, total)], total, nil
+}
+
+// Version returns one recorded version of a tileset.
+func (s *TileService) Version(name, id string) (TileVersion, bool) {
+	versions, err := s.Versions(name)
+	if err != nil {
+		return TileVersion{}, false
+	}
+	for _, v := range versions {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return TileVersion{}, false
+}
+
+// Commit publishes a validated archive at tmpPath as the current version
+// of tileset name, recording it in the manifest and pruning old versions.
+// An untracked archive already at name is recorded first so it is kept.
+func (s *TileService) Commit(tmpPath, name string, version TileVersion) (TileVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.loadManifest(name)
+	if err != nil {
+		return TileVersion{}, err
+	}
+	outputPath := filepath.Join(s.tilesDir, name)
+	_, statErr := os.Stat(outputPath)
+	if m.Current == "" {
+		if statErr == nil {
+			prior, err := s.snapshot(outputPath, name, TileVersion{})
+			if err != nil {
+				return TileVersion{}, fmt.Errorf("recording previous version: %w", err)
+			}
+			m.Versions = append(m.Versions, prior)
+		}
+	}
+
+	v, err := s.snapshot(tmpPath, name, version)
+	if err != nil {
+		return TileVersion{}, err
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		return TileVersion{}, err
+	}
+
+	// Regenerating identical content moves the existing version to the front
+	m.Versions = slices.DeleteFunc(m.Versions, func(old TileVersion) bool { return old.ID == v.ID })
+	m.Versions = append([]TileVersion{v}, m.Versions...)
+	m.Current = v.ID
+	s.prune(name, &m)
+	s.cache.evict(name)
+	if err := s.saveManifest(name, m); err != nil {
+		return TileVersion{}, err
+	}
+
+	action := "updated"
+	if statErr != nil {
+		action = "created"
+	}
+	DefaultBus.Publish(Event{Resource: "tiles", Action: action, ID: name})
+	v.Current = true
+	v.URL = VersionURL(name, v.ID)
+	return v, nil
+}
+
+// Rollback makes a recorded version the current archive of a tileset.
+func (s *TileService) Rollback(name, id string) (TileVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := validTileName(name); err != nil {
+		return TileVersion{}, err
+	}
+	m, err := s.loadManifest(name)
+	if err != nil {
+		return TileVersion{}, err
+	}
+	i := slices.IndexFunc(m.Versions, func(v TileVersion) bool { return v.ID == id })
+	if i < 0 {
+		return TileVersion{}, fmt.Errorf("%w: %q of %s", ErrVersionNotFound, id, name)
+	}
+
+	// Link the version next to the tileset, then rename it into place
+	tmp, err := os.CreateTemp(s.tilesDir, "."+strings.TrimSuffix(name, ".pmtiles")+"-*.pmtiles")
+	if err != nil {
+		return TileVersion{}, err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath) // fails harmlessly once renamed
+	if err := linkOrCopy(s.VersionPath(name, id), tmpPath); err != nil {
+		return TileVersion{}, err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.tilesDir, name)); err != nil {
+		return TileVersion{}, err
+	}
+	s.cache.evict(name)
+
+	m.Current = id
+	if err := s.saveManifest(name, m); err != nil {
+		return TileVersion{}, err
+	}
+
+	DefaultBus.Publish(Event{Resource: "tiles", Action: "updated", ID: name})
+	return s.annotate(name, m)[i], nil
+}
+
+// snapshot stores a copy of the archive at path as a version of tileset
+// name, named by its content hash.
+func (s *TileService) snapshot(path, name string, v TileVersion) (TileVersion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TileVersion{}, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return TileVersion{}, err
+	}
+
+	v.ID = hex.EncodeToString(h.Sum(nil))[:12]
+	v.Size = pmtiles.FormatSize(size)
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = time.Now().UTC()
+	}
+	if err := os.MkdirAll(s.versionDir(name), 0755); err != nil {
+		return TileVersion{}, err
+	}
+	dst := s.VersionPath(name, v.ID)
+	if _, err := os.Stat(dst); err == nil {
+		return v, nil
+	}
+	return v, linkOrCopy(path, dst)
+}
+
+// prune drops versions beyond maxTileVersions prior ones, keeping the
+// current version and any pinned by a layer.
+func (s *TileService) prune(name string, m *tileManifest) {
+	pinned := s.pinned(name)
+	kept := m.Versions[:0]
+	prior := 0
+	for _, v := range m.Versions {
+		switch {
+		case v.ID == m.Current:
+		case len(pinned[v.ID]) > 0:
+		case prior < maxTileVersions:
+			prior++
+		default:
+			os.Remove(s.VersionPath(name, v.ID))
+			continue
+		}
+		kept = append(kept, v)
+	}
+	m.Versions = kept
+}
+
+// pinned maps version IDs of tileset name to the layers pinned to them.
+func (s *TileService) pinned(name string) map[string][]string {
+	pinned := make(map[string][]string)
+	if s.layers == nil {
+		return pinned
+	}
+	for id, layer := range s.layers.List() {
+		if layer.File == name && layer.Version != "" {
+			pinned[layer.Version] = append(pinned[layer.Version], id)
+		}
+	}
+	for _, ids := range pinned {
+		slices.Sort(ids)
+	}
+	return pinned
+}
+
+// annotate fills in the fields of a manifest's versions derived at read time.
+func (s *TileService) annotate(name string, m tileManifest) []TileVersion {
+	pinned := s.pinned(name)
+	versions := make([]TileVersion, len(m.Versions))
+	for i, v := range m.Versions {
+		v.Current = v.ID == m.Current
+		v.PinnedBy = pinned[v.ID]
+		v.URL = VersionURL(name, v.ID)
+		versions[i] = v
+	}
+	return versions
+}
+
+// manifestFile returns the path to a tileset's version manifest.
+func (s *TileService) manifestFile(name string) string {
+	return filepath.Join(s.versionDir(name), "manifest.json")
+}
+
+func (s *TileService) loadManifest(name string) (tileManifest, error) {
+	var m tileManifest
+	data, err := os.ReadFile(s.manifestFile(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("reading version manifest of %s: %w", name, err)
+	}
+	return m, nil
+}
+
+func (s *TileService) saveManifest(name string, m tileManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := s.manifestFile(name)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// validTileName rejects tileset names that are not plain .pmtiles files.
+func validTileName(name string) error {
+	if filepath.Ext(name) != ".pmtiles" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("%w %q", ErrInvalidTileName, name)
+	}
+	return nil
+}
+
+// linkOrCopy hard-links src to dst, copying when links are unsupported.
+func linkOrCopy(src, dst string) error {
+	os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
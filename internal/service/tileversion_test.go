@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeblew999/plat-geo/internal/tiler"
+	"github.com/joeblew999/plat-geo/internal/tiler/gotiler"
+)
+
+// newTestTiles returns a tile service with its layer service over a
+// temporary data directory.
+func newTestTiles(t *testing.T) (*TileService, *LayerService) {
+	t.Helper()
+	dataDir := t.TempDir()
+	layers := NewLayerService(dataDir)
+	tiles := NewTileService(dataDir, layers)
+	if err := os.MkdirAll(tiles.TilesDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return tiles, layers
+}
+
+// commitContent commits content as a new version of tileset name.
+func commitContent(t *testing.T, s *TileService, name, content string) TileVersion {
+	t.Helper()
+	tmp := filepath.Join(s.TilesDir(), ".commit.pmtiles")
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := s.Commit(tmp, name, TileVersion{Sources: []string{content + ".geojson"}, Engine: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// commitArchive tiles pointsGeoJSON as layer "points" at zooms 0 to
+// maxZoom with the go engine, and commits it as a new version of tileset
+// name.
+func commitArchive(t *testing.T, s *TileService, name string, maxZoom int) TileVersion {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "points.geojson")
+	if err := os.WriteFile(src, []byte(pointsGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(s.TilesDir(), ".commit.pmtiles")
+	config := tiler.TileConfig{Layer: "points", MaxZoom: maxZoom}
+	if err := gotiler.New().Tile(context.Background(), src, tmp, config, nil); err != nil {
+		t.Fatal(err)
+	}
+	v, err := s.Commit(tmp, name, TileVersion{Sources: []string{"points.geojson"}, Engine: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// sameFile reports whether two paths are hard links to one file.
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	ai, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return os.SameFile(ai, bi)
+}
+
+func TestTileVersionCommit(t *testing.T) {
+	s, _ := newTestTiles(t)
+	current := filepath.Join(s.TilesDir(), "roads.pmtiles")
+
+	v1 := commitContent(t, s, "roads.pmtiles", "first")
+	v2 := commitContent(t, s, "roads.pmtiles", "second")
+	if v1.ID == v2.ID {
+		t.Fatalf("different content committed with the same ID %s", v1.ID)
+	}
+	if !v2.Current || v2.URL != VersionURL("roads.pmtiles", v2.ID) {
+		t.Errorf("committed version = current %v, url %q", v2.Current, v2.URL)
+	}
+
+	// The manifest lists both versions, newest first, with the second current
+	data, err := os.ReadFile(s.manifestFile("roads.pmtiles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m tileManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Current != v2.ID || len(m.Versions) != 2 || m.Versions[0].ID != v2.ID || m.Versions[1].ID != v1.ID {
+		t.Fatalf("manifest = %+v, want current %s and versions [%s %s]", m, v2.ID, v2.ID, v1.ID)
+	}
+	if got := m.Versions[1].Sources; len(got) != 1 || got[0] != "first.geojson" {
+		t.Errorf("first version sources = %v", got)
+	}
+
+	// The served archive is a hard link to the current version's file
+	if !sameFile(t, current, s.VersionPath("roads.pmtiles", v2.ID)) {
+		t.Error("roads.pmtiles is not linked to the current version")
+	}
+	if data, _ := os.ReadFile(s.VersionPath("roads.pmtiles", v1.ID)); string(data) != "first" {
+		t.Errorf("first version holds %q", data)
+	}
+
+	// Committing identical content moves the existing version to the front
+	again := commitContent(t, s, "roads.pmtiles", "first")
+	versions, err := s.Versions("roads.pmtiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != v1.ID || len(versions) != 2 || versions[0].ID != v1.ID || !versions[0].Current || versions[1].Current {
+		t.Errorf("versions after recommitting = %+v", versions)
+	}
+}
+
+func TestTileVersionCommitKeepsUntrackedArchive(t *testing.T) {
+	s, _ := newTestTiles(t)
+	if err := os.WriteFile(filepath.Join(s.TilesDir(), "roads.pmtiles"), []byte("uploaded"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := commitContent(t, s, "roads.pmtiles", "generated")
+	versions, err := s.Versions("roads.pmtiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].ID != v.ID {
+		t.Fatalf("versions = %+v, want the generated one and the untracked one", versions)
+	}
+	if data, _ := os.ReadFile(s.VersionPath("roads.pmtiles", versions[1].ID)); string(data) != "uploaded" {
+		t.Errorf("untracked archive recorded as %q", data)
+	}
+}
+
+func TestTileVersionRollback(t *testing.T) {
+	s, _ := newTestTiles(t)
+	current := filepath.Join(s.TilesDir(), "roads.pmtiles")
+	v1 := commitContent(t, s, "roads.pmtiles", "first")
+	v2 := commitContent(t, s, "roads.pmtiles", "second")
+
+	rolled, err := s.Rollback("roads.pmtiles", v1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolled.ID != v1.ID || !rolled.Current {
+		t.Errorf("rolled back to %+v", rolled)
+	}
+	if data, _ := os.ReadFile(current); string(data) != "first" {
+		t.Errorf("served archive holds %q after rollback, want first", data)
+	}
+	if !sameFile(t, current, s.VersionPath("roads.pmtiles", v1.ID)) {
+		t.Error("roads.pmtiles is not linked to the rolled back version")
+	}
+
+	// Both versions are kept, in their original order
+	versions, err := s.Versions("roads.pmtiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].ID != v2.ID || versions[0].Current || !versions[1].Current {
+		t.Errorf("versions after rollback = %+v", versions)
+	}
+
+	if _, err := s.Rollback("roads.pmtiles", "000000000000"); err == nil {
+		t.Error("rolled back to an unknown version")
+	}
+	if _, err := s.Rollback("../roads.pmtiles", v1.ID); err == nil {
+		t.Error("rolled back an invalid tileset name")
+	}
+}
+
+func TestTileVersionPruneKeepsPinned(t *testing.T) {
+	s, layers := newTestTiles(t)
+
+	pinned := commitContent(t, s, "roads.pmtiles", "v0")
+	if _, err := layers.Create(LayerConfig{ID: "old-roads", Name: "Old roads", File: "roads.pmtiles", Version: pinned.ID, GeomType: "line"}); err != nil {
+		t.Fatal(err)
+	}
+	unpinned := commitContent(t, s, "roads.pmtiles", "v1")
+	var last TileVersion
+	for i := 2; i <= maxTileVersions+3; i++ {
+		last = commitContent(t, s, "roads.pmtiles", fmt.Sprintf("v%d", i))
+	}
+
+	versions, err := s.Versions("roads.pmtiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The current version, maxTileVersions prior ones and the pinned one
+	if len(versions) != maxTileVersions+2 {
+		t.Fatalf("kept %d versions, want %d", len(versions), maxTileVersions+2)
+	}
+	if versions[0].ID != last.ID || !versions[0].Current {
+		t.Errorf("newest version = %+v, want current %s", versions[0], last.ID)
+	}
+	oldest := versions[len(versions)-1]
+	if oldest.ID != pinned.ID || len(oldest.PinnedBy) != 1 || oldest.PinnedBy[0] != "old-roads" {
+		t.Errorf("oldest kept version = %+v, want %s pinned by old-roads", oldest, pinned.ID)
+	}
+	if _, err := os.Stat(s.VersionPath("roads.pmtiles", pinned.ID)); err != nil {
+		t.Errorf("pinned version file removed: %v", err)
+	}
+
+	for _, v := range versions {
+		if v.ID == unpinned.ID {
+			t.Errorf("unpinned version %s beyond the limit was kept", v.ID)
+		}
+	}
+	if _, err := os.Stat(s.VersionPath("roads.pmtiles", unpinned.ID)); !os.IsNotExist(err) {
+		t.Errorf("pruned version file still exists: %v", err)
+	}
+}
+
+// cached reports whether the tile service holds archive name open.
+func cached(s *TileService, name string) bool {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	_, ok := s.cache.archives[name]
+	return ok
+}
+
+func TestArchiveCacheEviction(t *testing.T) {
+	s, _ := newTestTiles(t)
+	t.Cleanup(s.Close)
+	v1 := commitArchive(t, s, "roads.pmtiles", 2)
+	version := "versions/roads/" + v1.ID + ".pmtiles"
+
+	open := func(name string) {
+		t.Helper()
+		_, release, err := s.Archive(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	steps := []struct {
+		desc string
+		run  func() error
+	}{
+		{"commit", func() error { commitArchive(t, s, "roads.pmtiles", 3); return nil }},
+		{"rollback", func() error { _, err := s.Rollback("roads.pmtiles", v1.ID); return err }},
+		{"rename", func() error { _, err := s.Rename("roads.pmtiles", "streets.pmtiles"); return err }},
+	}
+	for _, step := range steps {
+		open("roads.pmtiles")
+		open(version)
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.desc, err)
+		}
+		if cached(s, "roads.pmtiles") || cached(s, version) {
+			t.Errorf("%s left roads.pmtiles or its version open", step.desc)
+		}
+	}
+
+	// A reader still in use survives deletion until it is released
+	r, release, err := s.Archive("streets.pmtiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("streets.pmtiles", true); err != nil {
+		t.Fatal(err)
+	}
+	if cached(s, "streets.pmtiles") {
+		t.Error("deleted tileset is still cached")
+	}
+	if _, _, err := r.Tile(0, 0, 0); err != nil {
+		t.Errorf("reader in use was closed by delete: %v", err)
+	}
+	release()
+	if _, _, err := r.Tile(0, 0, 0); err == nil {
+		t.Error("reader of a deleted tileset still open after release")
+	}
+}
+
+func TestArchiveCacheBounded(t *testing.T) {
+	s, _ := newTestTiles(t)
+	t.Cleanup(s.Close)
+	commitArchive(t, s, "roads.pmtiles", 0)
+	data, err := os.ReadFile(filepath.Join(s.TilesDir(), "roads.pmtiles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, maxOpenArchives+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("copy-%d.pmtiles", i)
+		if err := os.WriteFile(filepath.Join(s.TilesDir(), names[i]), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	open := func(name string) {
+		t.Helper()
+		_, release, err := s.Archive(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	for _, name := range names[:maxOpenArchives] {
+		open(name)
+	}
+	// Using the first copy again leaves the second least recently used
+	open(names[0])
+	open(names[maxOpenArchives])
+
+	if n := len(s.cache.archives); n != maxOpenArchives {
+		t.Errorf("%d archives open, want %d", n, maxOpenArchives)
+	}
+	if !cached(s, names[0]) || cached(s, names[1]) || !cached(s, names[maxOpenArchives]) {
+		t.Errorf("evicted the wrong archive: %s open %v, %s open %v", names[0], cached(s, names[0]), names[1], cached(s, names[1]))
+	}
+}
+
+func TestTileDescribeLayers(t *testing.T) {
+	s, _ := newTestTiles(t)
+	t.Cleanup(s.Close)
+	commitArchive(t, s, "points.pmtiles", 4)
+
+	// A second tileset holds a line layer next to the points
+	dir := t.TempDir()
+	points, roads := filepath.Join(dir, "points.geojson"), filepath.Join(dir, "roads.geojson")
+	if err := os.WriteFile(points, []byte(pointsGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	roadsGeoJSON := `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"101"},"geometry":{"type":"LineString","coordinates":[[-122.4,37.8],[-122.2,37.5]]}}]}`
+	if err := os.WriteFile(roads, []byte(roadsGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(s.TilesDir(), ".commit.pmtiles")
+	layers := []tiler.LayerInput{
+		{Path: points, Config: tiler.TileConfig{Layer: "cities", MinZoom: 2, MaxZoom: 6}},
+		{Path: roads, Config: tiler.TileConfig{Layer: "roads", MinZoom: 4, MaxZoom: 8}},
+	}
+	if err := gotiler.New().TileLayers(context.Background(), layers, tmp, tiler.TileConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Commit(tmp, "city.pmtiles", TileVersion{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		minZoom    int
+		maxZoom    int
+		wantLayers []TileLayer
+	}{
+		{"points.pmtiles", 0, 4, []TileLayer{{Name: "points", GeomType: "point"}}},
+		{"city.pmtiles", 2, 8, []TileLayer{{Name: "cities", GeomType: "point"}, {Name: "roads", GeomType: "line"}}},
+	}
+	for _, tt := range tests {
+		f, err := s.Get(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Error != "" {
+			t.Errorf("%s: %s", tt.name, f.Error)
+		}
+		if f.TileType != "mvt" || f.MinZoom != tt.minZoom || f.MaxZoom != tt.maxZoom {
+			t.Errorf("%s: %s tiles at zooms %d-%d, want mvt at %d-%d", tt.name, f.TileType, f.MinZoom, f.MaxZoom, tt.minZoom, tt.maxZoom)
+		}
+		if len(f.Bounds) != 4 || f.Bounds[0] > -122.4 || f.Bounds[2] < -122.2 {
+			t.Errorf("%s: bounds %v do not cover the features", tt.name, f.Bounds)
+		}
+		if len(f.Layers) != len(tt.wantLayers) {
+			t.Errorf("%s: layers %+v, want %+v", tt.name, f.Layers, tt.wantLayers)
+			continue
+		}
+		for i := range tt.wantLayers {
+			if f.Layers[i] != tt.wantLayers[i] {
+				t.Errorf("%s: layers %+v, want %+v", tt.name, f.Layers, tt.wantLayers)
+				break
+			}
+		}
+	}
+}
+
+func TestTileRename(t *testing.T) {
+	s, layers := newTestTiles(t)
+	t.Cleanup(s.Close)
+	v := commitArchive(t, s, "roads.pmtiles", 2)
+	commitArchive(t, s, "taken.pmtiles", 2)
+	if _, err := layers.Create(LayerConfig{ID: "roads", Name: "Roads", File: "roads.pmtiles", Version: v.ID, GeomType: "line"}); err != nil {
+		t.Fatal(err)
+	}
+
+	errTests := []struct {
+		from, to string
+		want     error
+	}{
+		{"missing.pmtiles", "streets.pmtiles", ErrTileNotFound},
+		{"roads.pmtiles", "taken.pmtiles", ErrTileExists},
+		{"roads.pmtiles", "../streets.pmtiles", ErrInvalidTileName},
+	}
+	for _, tt := range errTests {
+		if _, err := s.Rename(tt.from, tt.to); !errors.Is(err, tt.want) {
+			t.Errorf("rename %s to %s: err %v, want %v", tt.from, tt.to, err, tt.want)
+		}
+	}
+
+	f, err := s.Rename("roads.pmtiles", "streets.pmtiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "streets.pmtiles" || len(f.Layers) != 1 {
+		t.Errorf("renamed tileset = %+v", f)
+	}
+	if _, err := os.Stat(filepath.Join(s.TilesDir(), "roads.pmtiles")); !os.IsNotExist(err) {
+		t.Errorf("old file still exists: %v", err)
+	}
+	if !sameFile(t, filepath.Join(s.TilesDir(), "streets.pmtiles"), s.VersionPath("streets.pmtiles", v.ID)) {
+		t.Error("streets.pmtiles is not linked to its moved version")
+	}
+	if layer, _ := layers.Get("roads"); layer.File != "streets.pmtiles" || layer.Version != v.ID {
+		t.Errorf("layer = file %s version %s, want streets.pmtiles pinned to %s", layer.File, layer.Version, v.ID)
+	}
+}
+
+func TestTileRenameRestoresFileWhenVersionsFail(t *testing.T) {
+	s, _ := newTestTiles(t)
+	t.Cleanup(s.Close)
+	v := commitArchive(t, s, "roads.pmtiles", 2)
+
+	// A dangling symlink passes the existence check, but a directory
+	// cannot be renamed over it
+	if err := os.Symlink("nowhere", s.versionDir("streets.pmtiles")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if _, err := s.Rename("roads.pmtiles", "streets.pmtiles"); err == nil || !strings.Contains(err.Error(), "failed to move versions") {
+		t.Fatalf("err = %v, want a versions move failure", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(s.TilesDir(), "streets.pmtiles")); !os.IsNotExist(err) {
+		t.Errorf("file left under the new name: %v", err)
+	}
+	if !sameFile(t, filepath.Join(s.TilesDir(), "roads.pmtiles"), s.VersionPath("roads.pmtiles", v.ID)) {
+		t.Error("roads.pmtiles was not restored next to its versions")
+	}
+}
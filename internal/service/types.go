@@ -1,6 +1,8 @@
 // Package service contains business logic for the plat-geo platform.
 package service
 
+import "time"
+
 // LayerConfig represents a map layer configuration.
 // Single source of truth: Huma reads tags for OpenAPI + validation,
 // cmd/humastargen reads tags for Datastar signal helpers + HTML forms.
@@ -15,6 +17,7 @@ type LayerConfig struct {
 	ID             string       `json:"id,omitempty" doc:"Unique layer identifier" example:"buildings" card:"id"`
 	Name           string       `json:"name" required:"true" minLength:"1" maxLength:"100" doc:"Display name" example:"Buildings" card:"title"`
 	File           string       `json:"file" required:"true" doc:"Source file name" example:"buildings.pmtiles" input:"sse" sse:"/api/v1/editor/tiles/select,pmtiles-select" card:"meta"`
+	Version        string       `json:"version,omitempty" doc:"Pinned tileset version; empty follows the current one" example:"3f9a1c2b7d4e"`
 	PMTilesLayer   string       `json:"pmtilesLayer,omitempty" doc:"Layer name within PMTiles" example:"buildings" default:"default"`
 	GeomType       string       `json:"geomType" required:"true" enum:"polygon,line,point" doc:"Geometry type" example:"polygon" default:"polygon" card:"meta"`
 	DefaultVisible bool         `json:"defaultVisible" default:"true" doc:"Whether layer is visible by default" example:"true" signal:"visible"`
@@ -56,11 +59,40 @@ type Style struct {
 type SourceFile struct {
 	Name     string `json:"name" doc:"File name" example:"buildings.geojson" card:"title"`
 	Size     string `json:"size" doc:"Human-readable file size" example:"1.2 MB" card:"meta"`
-	FileType string `json:"fileType" doc:"File type: GeoJSON or GeoParquet" example:"GeoJSON" card:"badge"`
+	FileType string `json:"fileType" doc:"File type: GeoJSON, GeoJSONSeq, GeoParquet or FlatGeobuf" example:"GeoJSON" card:"badge"`
 }
 
-// TileFile represents a PMTiles file.
+// TileFile represents a PMTiles file, described from its header and
+// metadata. Archive fields are empty when the file can't be read.
 type TileFile struct {
-	Name string `json:"name" doc:"PMTiles file name" example:"buildings.pmtiles"`
-	Size string `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	Name           string      `json:"name" doc:"PMTiles file name" example:"buildings.pmtiles"`
+	Size           string      `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	Bytes          int64       `json:"bytes" doc:"File size in bytes" example:"5662310"`
+	ModTime        time.Time   `json:"modTime" doc:"Last modification time"`
+	TileType       string      `json:"tileType,omitempty" doc:"Tile format" example:"mvt"`
+	Compression    string      `json:"compression,omitempty" doc:"Tile compression" example:"gzip"`
+	MinZoom        int         `json:"minZoom" doc:"Minimum zoom level" example:"0"`
+	MaxZoom        int         `json:"maxZoom" doc:"Maximum zoom level" example:"14"`
+	Bounds         []float64   `json:"bounds,omitempty" doc:"Bounds as west, south, east, north" example:"[-122.5,37.7,-122.3,37.8]"`
+	Center         []float64   `json:"center,omitempty" doc:"Default view as longitude, latitude, zoom" example:"[-122.4,37.75,10]"`
+	AddressedTiles uint64      `json:"addressedTiles" doc:"Number of tiles addressed by the directory"`
+	TileEntries    uint64      `json:"tileEntries" doc:"Number of directory entries, after run-length encoding"`
+	TileContents   uint64      `json:"tileContents" doc:"Number of distinct tile contents"`
+	Layers         []TileLayer `json:"layers,omitempty" doc:"Vector layers in the tiles"`
+	Error          string      `json:"error,omitempty" doc:"Why the archive could not be read"`
+}
+
+// TileLayer describes a vector layer within a PMTiles file.
+type TileLayer struct {
+	Name     string `json:"name" doc:"Layer name" example:"buildings"`
+	GeomType string `json:"geomType,omitempty" enum:"polygon,line,point" doc:"Dominant geometry type, when known" example:"polygon"`
+}
+
+// TileVerification reports the outcome of checking a PMTiles file.
+type TileVerification struct {
+	Name     string   `json:"name" doc:"Tileset file name" example:"buildings.pmtiles"`
+	Valid    bool     `json:"valid" doc:"Whether no problems were found"`
+	Entries  uint64   `json:"entries" doc:"Tile directory entries checked" example:"1250"`
+	Tiles    uint64   `json:"tiles" doc:"Distinct tiles decoded" example:"1180"`
+	Problems []string `json:"problems" doc:"Problems found, empty when valid"`
 }
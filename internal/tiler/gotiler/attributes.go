@@ -0,0 +1,150 @@
+package gotiler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/joeblew999/plat-geo/internal/tiler"
+)
+
+// attributeFilter applies a config's attribute settings to properties.
+// Include, exclude and type coercion run once per feature as it is read;
+// per-zoom stripping runs as each tile is encoded.
+type attributeFilter struct {
+	include map[string]bool // nil keeps every property
+	exclude map[string]bool
+	types   map[string]string
+	minZoom map[string]int
+}
+
+// newAttributeFilter returns nil when config keeps properties unchanged.
+func newAttributeFilter(config tiler.TileConfig) (*attributeFilter, error) {
+	if err := config.ValidateAttributes(); err != nil {
+		return nil, err
+	}
+	if len(config.Include) == 0 && len(config.Exclude) == 0 &&
+		len(config.AttributeTypes) == 0 && len(config.AttributeMinZoom) == 0 {
+		return nil, nil
+	}
+
+	a := &attributeFilter{
+		exclude: make(map[string]bool, len(config.Exclude)),
+		types:   config.AttributeTypes,
+		minZoom: config.AttributeMinZoom,
+	}
+	if len(config.Include) > 0 {
+		a.include = make(map[string]bool, len(config.Include))
+		for _, name := range config.Include {
+			a.include[name] = true
+		}
+	}
+	for _, name := range config.Exclude {
+		a.exclude[name] = true
+	}
+	return a, nil
+}
+
+// apply filters and coerces props in place. Values that cannot be
+// coerced to their configured type are dropped.
+func (a *attributeFilter) apply(props geojson.Properties) {
+	if a == nil {
+		return
+	}
+	for name, v := range props {
+		if (a.include != nil && !a.include[name]) || a.exclude[name] {
+			delete(props, name)
+			continue
+		}
+		if typ, ok := a.types[name]; ok {
+			if cv, ok := coerce(v, typ); ok {
+				props[name] = cv
+			} else {
+				delete(props, name)
+			}
+		}
+	}
+}
+
+// forTile prepares stored props for a tile at zoom: properties that only
+// appear at deeper zooms are removed, and int properties, which the
+// feature spool round-trips as JSON numbers, become integers again so
+// they are encoded as MVT int values.
+func (a *attributeFilter) forTile(props geojson.Properties, zoom int) {
+	if a == nil {
+		return
+	}
+	for name, minZoom := range a.minZoom {
+		if zoom < minZoom {
+			delete(props, name)
+		}
+	}
+	for name, typ := range a.types {
+		if f, ok := props[name].(float64); ok && typ == "int" {
+			props[name] = int64(f)
+		}
+	}
+}
+
+// coerce converts a JSON property value to a tiler.AttributeTypes type.
+func coerce(v any, typ string) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+	switch typ {
+	case "string":
+		switch val := v.(type) {
+		case string:
+			return val, true
+		case float64:
+			return strconv.FormatFloat(val, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(val), true
+		}
+		return fmt.Sprint(v), true
+
+	case "float", "int":
+		var f float64
+		switch val := v.(type) {
+		case float64:
+			f = val
+		case bool:
+			if val {
+				f = 1
+			}
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil {
+				return nil, false
+			}
+			f = parsed
+		default:
+			return nil, false
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		if typ == "int" {
+			f = math.Trunc(f)
+		}
+		return f, true
+
+	case "bool":
+		switch val := v.(type) {
+		case bool:
+			return val, true
+		case float64:
+			return val != 0, true
+		case string:
+			switch strings.ToLower(strings.TrimSpace(val)) {
+			case "", "0", "false", "no", "null":
+				return false, true
+			}
+			return true, true
+		}
+	}
+	return nil, false
+}
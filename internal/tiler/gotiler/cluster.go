@@ -0,0 +1,120 @@
+package gotiler
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+)
+
+// tilePixels is the nominal on-screen size of a tile, used to convert
+// cluster distances in pixels to tile extent units.
+const tilePixels = 256
+
+// pointCluster collects the points merged into one feature.
+type pointCluster struct {
+	seed    orb.Point // first point; membership is measured from here
+	sum     orb.Point
+	members []*geojson.Feature
+	index   int // position of the cluster in the output
+}
+
+// clusterPoints merges points, in tile coordinates, that lie within
+// distance extent units of an earlier point into a single feature at the
+// members' mean position. Clusters carry cluster=true, point_count and the
+// sum, min and max of each aggregate property; lone points and other
+// geometries pass through unchanged. Output keeps source order, with each
+// cluster at the position of its first point.
+func clusterPoints(features []*geojson.Feature, distance float64, aggregate []string) []*geojson.Feature {
+	if distance <= 0 {
+		return features
+	}
+
+	out := make([]*geojson.Feature, 0, len(features))
+	grid := make(map[[2]int][]*pointCluster)
+	var clusters []*pointCluster
+	d2 := distance * distance
+
+	for _, f := range features {
+		p, ok := f.Geometry.(orb.Point)
+		if !ok {
+			out = append(out, f)
+			continue
+		}
+
+		// Seeds within distance can only be in the neighbouring grid cells
+		cell := [2]int{int(math.Floor(p[0] / distance)), int(math.Floor(p[1] / distance))}
+		var best *pointCluster
+		bestD2 := d2
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for _, c := range grid[[2]int{cell[0] + dx, cell[1] + dy}] {
+					ex, ey := c.seed[0]-p[0], c.seed[1]-p[1]
+					d := ex*ex + ey*ey
+					if d < bestD2 || (d == bestD2 && (best == nil || c.index < best.index)) {
+						best, bestD2 = c, d
+					}
+				}
+			}
+		}
+
+		if best == nil {
+			best = &pointCluster{seed: p, index: len(out)}
+			grid[cell] = append(grid[cell], best)
+			clusters = append(clusters, best)
+			out = append(out, f)
+		}
+		best.sum[0] += p[0]
+		best.sum[1] += p[1]
+		best.members = append(best.members, f)
+	}
+
+	for _, c := range clusters {
+		if len(c.members) > 1 {
+			out[c.index] = c.feature(aggregate)
+		}
+	}
+	return out
+}
+
+// feature builds the merged feature for a cluster.
+func (c *pointCluster) feature(aggregate []string) *geojson.Feature {
+	n := float64(len(c.members))
+	f := geojson.NewFeature(orb.Point{c.sum[0] / n, c.sum[1] / n})
+	f.Properties["cluster"] = true
+	f.Properties["point_count"] = len(c.members)
+
+	for _, name := range aggregate {
+		var sum, lo, hi float64
+		seen := false
+		for _, m := range c.members {
+			var v float64
+			switch val := m.Properties[name].(type) {
+			case float64:
+				v = val
+			case int64:
+				v = float64(val)
+			default:
+				continue
+			}
+			if !seen {
+				lo, hi, seen = v, v, true
+			}
+			sum += v
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		if seen {
+			f.Properties[name+"_sum"] = sum
+			f.Properties[name+"_min"] = lo
+			f.Properties[name+"_max"] = hi
+		}
+	}
+	return f
+}
+
+// clusterDistance converts a distance in pixels to tile extent units.
+func clusterDistance(pixels int) float64 {
+	return float64(pixels) * mvt.DefaultExtent / tilePixels
+}
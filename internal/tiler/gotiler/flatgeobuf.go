@@ -0,0 +1,593 @@
+package gotiler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// FlatGeobuf format: https://flatgeobuf.org and its header.fbs/feature.fbs
+// schemas. Tables are read with the flatbuffers runtime directly rather
+// than generated accessors; field N of a table is at vtable slot 4+2N.
+
+// fgbMagic starts every FlatGeobuf file; the last byte is the patch
+// version and may differ.
+var fgbMagic = []byte{'f', 'g', 'b', 3, 'f', 'g', 'b'}
+
+// fgbNodeSize is the size of a packed R-tree node: a bounding box of four
+// float64s and a uint64 offset.
+const fgbNodeSize = 40
+
+// FlatGeobuf geometry types.
+const (
+	fgbUnknown byte = iota
+	fgbPoint
+	fgbLineString
+	fgbPolygon
+	fgbMultiPoint
+	fgbMultiLineString
+	fgbMultiPolygon
+	fgbGeometryCollection
+)
+
+// FlatGeobuf column types.
+const (
+	fgbByte byte = iota
+	fgbUByte
+	fgbBool
+	fgbShort
+	fgbUShort
+	fgbInt
+	fgbUInt
+	fgbLong
+	fgbULong
+	fgbFloat
+	fgbDouble
+	fgbString
+	fgbJSON
+	fgbDateTime
+	fgbBinary
+)
+
+// fgbColumn is a property column declared in the header.
+type fgbColumn struct {
+	name string
+	typ  byte
+}
+
+// flatgeobufReader streams features from a FlatGeobuf file. Features are
+// also readable by offset, so the tiler references them in place instead
+// of spooling a copy. When the file has a spatial index, pass 2 looks up
+// each tile's features through it rather than pass 1 recording every
+// feature a tile touches.
+type flatgeobufReader struct {
+	f        *os.File
+	r        *bufio.Reader
+	size     uint64
+	geomType byte
+	columns  []fgbColumn
+
+	offset uint64     // file offset of the next feature
+	last   featureRef // location of the feature last returned by Next
+
+	index  *fgbIndex     // nil when the file has no index
+	leaves *bufio.Reader // the index's leaf nodes, read alongside the features
+}
+
+func openFlatGeobuf(f *os.File) (*flatgeobufReader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	r := &flatgeobufReader{f: f, r: bufio.NewReaderSize(f, 1<<20), size: uint64(info.Size())}
+
+	var prefix [12]byte
+	if _, err := io.ReadFull(r.r, prefix[:]); err != nil {
+		return nil, fmt.Errorf("reading flatgeobuf header: %w", err)
+	}
+	if !bytes.Equal(prefix[:7], fgbMagic) {
+		return nil, errors.New("not a flatgeobuf v3 file")
+	}
+	headerLen := binary.LittleEndian.Uint32(prefix[8:12])
+	if uint64(headerLen) > r.size {
+		return nil, errors.New("corrupt flatgeobuf header")
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		return nil, fmt.Errorf("reading flatgeobuf header: %w", err)
+	}
+
+	var count uint64
+	var nodeSize uint16
+	if err := safeDecode(func() {
+		h := fbRoot(header)
+		r.geomType = h.uint8Field(2, fgbUnknown)
+		r.columns = fgbColumns(h, 7)
+		count = h.uint64Field(8, 0)
+		nodeSize = h.uint16Field(9, 16)
+	}); err != nil {
+		return nil, fmt.Errorf("parsing flatgeobuf header: %w", err)
+	}
+
+	// The packed R-tree precedes the features. Its leaves are checked
+	// against the features as they are read, so searches can be trusted
+	indexOffset := 12 + uint64(headerLen)
+	indexSize := fgbIndexSize(count, nodeSize)
+	r.offset = indexOffset + indexSize
+	if r.offset > r.size {
+		return nil, errors.New("corrupt flatgeobuf index")
+	}
+	if indexSize > 0 {
+		r.index = newFGBIndex(f, indexOffset, r.offset, count, nodeSize)
+		leaves := r.index.levels[0]
+		r.leaves = bufio.NewReaderSize(io.NewSectionReader(f,
+			int64(indexOffset+leaves[0]*fgbNodeSize), int64((leaves[1]-leaves[0])*fgbNodeSize)), 1<<16)
+	}
+	if _, err := r.r.Discard(int(indexSize)); err != nil {
+		return nil, fmt.Errorf("skipping flatgeobuf index: %w", err)
+	}
+	return r, nil
+}
+
+// fgbIndex searches a FlatGeobuf file's packed Hilbert R-tree. The tree
+// is stored level by level from the root down, with one leaf per feature,
+// in feature order, last; a leaf holds its feature's offset within the
+// feature data and an internal node the index of its first child. Nodes
+// are read from the file as a search descends, so only the nodes visited
+// are held in memory.
+type fgbIndex struct {
+	f        *os.File
+	offset   uint64      // file offset of the tree
+	features uint64      // file offset of the feature data
+	nodeSize uint64      // children per internal node
+	levels   [][2]uint64 // node index range [start, end) of each level, leaves first
+}
+
+func newFGBIndex(f *os.File, offset, features, count uint64, nodeSize uint16) *fgbIndex {
+	x := &fgbIndex{f: f, offset: offset, features: features, nodeSize: uint64(max(nodeSize, 2))}
+
+	// Level sizes as in the reference generateLevelBounds
+	sizes := []uint64{count}
+	nodes := count
+	for level := count; ; {
+		level = (level + x.nodeSize - 1) / x.nodeSize
+		sizes = append(sizes, level)
+		nodes += level
+		if level == 1 {
+			break
+		}
+	}
+	for _, size := range sizes {
+		nodes -= size
+		x.levels = append(x.levels, [2]uint64{nodes, nodes + size})
+	}
+	return x
+}
+
+// search calls fn, in feature order, with the position and file offset
+// of each feature whose bounding box intersects b. It is safe for
+// concurrent use.
+func (x *fgbIndex) search(b orb.Bound, fn func(i, offset uint64) error) error {
+	root := len(x.levels) - 1
+	return x.searchNodes(root, x.levels[root][0], b, fn)
+}
+
+// searchNodes searches the nodes of a level that share a parent,
+// starting at node first.
+func (x *fgbIndex) searchNodes(level int, first uint64, b orb.Bound, fn func(i, offset uint64) error) error {
+	bounds := x.levels[level]
+	if first < bounds[0] || first >= bounds[1] {
+		return errors.New("corrupt flatgeobuf index")
+	}
+	end := min(first+x.nodeSize, bounds[1])
+	buf := make([]byte, (end-first)*fgbNodeSize)
+	if _, err := x.f.ReadAt(buf, int64(x.offset+first*fgbNodeSize)); err != nil {
+		return fmt.Errorf("reading flatgeobuf index: %w", err)
+	}
+
+	le := binary.LittleEndian
+	for i := uint64(0); i < end-first; i++ {
+		node := buf[i*fgbNodeSize:]
+		minX := math.Float64frombits(le.Uint64(node[0:]))
+		minY := math.Float64frombits(le.Uint64(node[8:]))
+		maxX := math.Float64frombits(le.Uint64(node[16:]))
+		maxY := math.Float64frombits(le.Uint64(node[24:]))
+		if minX > b.Max[0] || minY > b.Max[1] || maxX < b.Min[0] || maxY < b.Min[1] {
+			continue
+		}
+		offset := le.Uint64(node[32:])
+		var err error
+		if level == 0 {
+			err = fn(first+i-bounds[0], x.features+offset)
+		} else {
+			err = x.searchNodes(level-1, offset, b, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fgbIndexSize returns the byte size of the packed Hilbert R-tree for
+// count features, or zero when the file has no index. Like the reference
+// calcTreeSize, the tree always has a root above the leaves, even for a
+// single feature.
+func fgbIndexSize(count uint64, nodeSize uint16) uint64 {
+	if nodeSize == 0 || count == 0 {
+		return 0
+	}
+	n := uint64(max(nodeSize, 2))
+	nodes := count
+	for level := count; ; {
+		level = (level + n - 1) / n
+		nodes += level
+		if level == 1 {
+			break
+		}
+	}
+	return nodes * fgbNodeSize
+}
+
+// Next returns the next feature in file order.
+func (r *flatgeobufReader) Next() (*geojson.Feature, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.r, prefix[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading flatgeobuf feature: %w", err)
+	}
+	n := binary.LittleEndian.Uint32(prefix[:])
+	if r.offset+4+uint64(n) > r.size {
+		return nil, errors.New("corrupt flatgeobuf feature")
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, fmt.Errorf("reading flatgeobuf feature: %w", err)
+	}
+
+	r.last = featureRef{offset: r.offset, length: 4 + n}
+	r.offset += 4 + uint64(n)
+	if r.index != nil {
+		var node [fgbNodeSize]byte
+		if _, err := io.ReadFull(r.leaves, node[:]); err != nil {
+			return nil, errors.New("flatgeobuf has more features than its index")
+		}
+		if r.index.features+binary.LittleEndian.Uint64(node[32:]) != r.last.offset {
+			return nil, errors.New("flatgeobuf index is out of step with its features")
+		}
+	}
+	return r.decode(buf)
+}
+
+// location returns where the feature last returned by Next is stored.
+func (r *flatgeobufReader) location() featureRef {
+	return r.last
+}
+
+// extent is the range of offsets features can be stored at.
+func (r *flatgeobufReader) extent() uint64 {
+	return r.size
+}
+
+// readAt decodes the feature stored at ref.
+func (r *flatgeobufReader) readAt(ref featureRef) (*geojson.Feature, error) {
+	buf := make([]byte, ref.length)
+	if _, err := r.f.ReadAt(buf, int64(ref.offset)); err != nil {
+		return nil, fmt.Errorf("reading flatgeobuf feature: %w", err)
+	}
+	return r.decode(buf[4:])
+}
+
+// indexed reports whether the file has a spatial index to search.
+func (r *flatgeobufReader) indexed() bool {
+	return r.index != nil
+}
+
+// search calls fn, in feature order, with the position and offset of each
+// feature whose bounding box intersects b.
+func (r *flatgeobufReader) search(b orb.Bound, fn func(i, offset uint64) error) error {
+	return r.index.search(b, fn)
+}
+
+// readFeatureAt decodes the feature stored at a file offset.
+func (r *flatgeobufReader) readFeatureAt(offset uint64) (*geojson.Feature, error) {
+	var prefix [4]byte
+	if _, err := r.f.ReadAt(prefix[:], int64(offset)); err != nil {
+		return nil, fmt.Errorf("reading flatgeobuf feature: %w", err)
+	}
+	n := binary.LittleEndian.Uint32(prefix[:])
+	if offset+4+uint64(n) > r.size {
+		return nil, errors.New("corrupt flatgeobuf feature")
+	}
+	return r.readAt(featureRef{offset: offset, length: 4 + n})
+}
+
+// decode converts a Feature table to GeoJSON. A feature with a missing
+// or unsupported geometry is returned without one.
+func (r *flatgeobufReader) decode(buf []byte) (*geojson.Feature, error) {
+	f := geojson.NewFeature(nil)
+	err := safeDecode(func() {
+		t := fbRoot(buf)
+		if g, ok := t.table(0); ok {
+			f.Geometry = fgbGeometry(g, r.geomType)
+		}
+		columns := r.columns
+		if len(columns) == 0 {
+			columns = fgbColumns(t, 2)
+		}
+		if props := t.bytes(1); len(props) > 0 {
+			fgbProperties(props, columns, f.Properties)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decoding flatgeobuf feature: %w", err)
+	}
+	return f, nil
+}
+
+// Close closes the underlying file.
+func (r *flatgeobufReader) Close() error {
+	return r.f.Close()
+}
+
+// fgbGeometry decodes a Geometry table. typ is the header geometry type;
+// when it is Unknown each geometry carries its own.
+func fgbGeometry(g fbTable, typ byte) orb.Geometry {
+	if typ == fgbUnknown {
+		typ = g.uint8Field(6, fgbUnknown)
+	}
+	xy := g.float64s(1)
+	ends := g.uint32s(0)
+
+	points := func(xy []float64) []orb.Point {
+		ps := make([]orb.Point, len(xy)/2)
+		for i := range ps {
+			ps[i] = orb.Point{xy[2*i], xy[2*i+1]}
+		}
+		return ps
+	}
+	// Split coordinates into rings or lines at the ends offsets, which
+	// count coordinate pairs
+	split := func() [][]orb.Point {
+		if len(ends) == 0 {
+			return [][]orb.Point{points(xy)}
+		}
+		var out [][]orb.Point
+		start := uint32(0)
+		for _, end := range ends {
+			if int(end)*2 > len(xy) || end < start {
+				break
+			}
+			out = append(out, points(xy[start*2:end*2]))
+			start = end
+		}
+		return out
+	}
+
+	switch typ {
+	case fgbPoint:
+		if len(xy) < 2 {
+			return nil
+		}
+		return orb.Point{xy[0], xy[1]}
+	case fgbMultiPoint:
+		return orb.MultiPoint(points(xy))
+	case fgbLineString:
+		return orb.LineString(points(xy))
+	case fgbMultiLineString:
+		var mls orb.MultiLineString
+		for _, part := range split() {
+			mls = append(mls, orb.LineString(part))
+		}
+		return mls
+	case fgbPolygon:
+		var poly orb.Polygon
+		for _, part := range split() {
+			poly = append(poly, orb.Ring(part))
+		}
+		return poly
+	case fgbMultiPolygon:
+		var mp orb.MultiPolygon
+		for _, part := range g.tables(7) {
+			if poly, ok := fgbGeometry(part, fgbPolygon).(orb.Polygon); ok {
+				mp = append(mp, poly)
+			}
+		}
+		return mp
+	case fgbGeometryCollection:
+		var c orb.Collection
+		for _, part := range g.tables(7) {
+			if child := fgbGeometry(part, fgbUnknown); child != nil {
+				c = append(c, child)
+			}
+		}
+		return c
+	}
+	return nil
+}
+
+// fgbColumns reads a [Column] vector field.
+func fgbColumns(t fbTable, field int) []fgbColumn {
+	var columns []fgbColumn
+	for _, c := range t.tables(field) {
+		columns = append(columns, fgbColumn{name: c.string(0), typ: c.uint8Field(1, fgbByte)})
+	}
+	return columns
+}
+
+// fgbProperties decodes the property buffer: a sequence of uint16 column
+// indexes, each followed by a little-endian value of the column's type.
+// Numbers become float64 and binary values are skipped.
+func fgbProperties(b []byte, columns []fgbColumn, props geojson.Properties) {
+	le := binary.LittleEndian
+	for len(b) >= 2 {
+		i := int(le.Uint16(b))
+		b = b[2:]
+		if i >= len(columns) {
+			return
+		}
+		col := columns[i]
+
+		var v any
+		var n int
+		switch col.typ {
+		case fgbByte:
+			v, n = float64(int8(b[0])), 1
+		case fgbUByte:
+			v, n = float64(b[0]), 1
+		case fgbBool:
+			v, n = b[0] != 0, 1
+		case fgbShort:
+			v, n = float64(int16(le.Uint16(b))), 2
+		case fgbUShort:
+			v, n = float64(le.Uint16(b)), 2
+		case fgbInt:
+			v, n = float64(int32(le.Uint32(b))), 4
+		case fgbUInt:
+			v, n = float64(le.Uint32(b)), 4
+		case fgbLong:
+			v, n = float64(int64(le.Uint64(b))), 8
+		case fgbULong:
+			v, n = float64(le.Uint64(b)), 8
+		case fgbFloat:
+			v, n = float64(math.Float32frombits(le.Uint32(b))), 4
+		case fgbDouble:
+			v, n = math.Float64frombits(le.Uint64(b)), 8
+		case fgbString, fgbJSON, fgbDateTime, fgbBinary:
+			size := int(le.Uint32(b))
+			n = 4 + size
+			if col.typ != fgbBinary {
+				v = string(b[4:n])
+			}
+		default:
+			return
+		}
+		b = b[n:]
+		if v != nil {
+			props[col.name] = v
+		}
+	}
+}
+
+// safeDecode turns the panics the flatbuffers runtime raises on
+// truncated or corrupt buffers into errors.
+func safeDecode(fn func()) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("corrupt data: %v", p)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// fbTable wraps a flatbuffers table with accessors by field number.
+type fbTable struct {
+	flatbuffers.Table
+}
+
+func fbRoot(buf []byte) fbTable {
+	return fbTable{flatbuffers.Table{Bytes: buf, Pos: flatbuffers.GetUOffsetT(buf)}}
+}
+
+// field returns the absolute position of field n, or 0 if it is absent.
+func (t fbTable) field(n int) flatbuffers.UOffsetT {
+	o := flatbuffers.UOffsetT(t.Offset(flatbuffers.VOffsetT(4 + 2*n)))
+	if o == 0 {
+		return 0
+	}
+	return t.Pos + o
+}
+
+func (t fbTable) uint8Field(n int, def byte) byte {
+	if p := t.field(n); p != 0 {
+		return t.GetUint8(p)
+	}
+	return def
+}
+
+func (t fbTable) uint16Field(n int, def uint16) uint16 {
+	if p := t.field(n); p != 0 {
+		return t.GetUint16(p)
+	}
+	return def
+}
+
+func (t fbTable) uint64Field(n int, def uint64) uint64 {
+	if p := t.field(n); p != 0 {
+		return t.GetUint64(p)
+	}
+	return def
+}
+
+func (t fbTable) string(n int) string {
+	if p := t.field(n); p != 0 {
+		return t.String(p)
+	}
+	return ""
+}
+
+func (t fbTable) bytes(n int) []byte {
+	if p := t.field(n); p != 0 {
+		return t.ByteVector(p)
+	}
+	return nil
+}
+
+// vector returns where the elements of vector field n start and how many
+// there are. Unlike the other accessors, Vector and VectorLen take the
+// field position relative to the table.
+func (t fbTable) vector(n int) (flatbuffers.UOffsetT, int) {
+	p := t.field(n)
+	if p == 0 {
+		return 0, 0
+	}
+	return t.Vector(p - t.Pos), t.VectorLen(p - t.Pos)
+}
+
+func (t fbTable) float64s(n int) []float64 {
+	start, count := t.vector(n)
+	out := make([]float64, count)
+	for i := range out {
+		out[i] = flatbuffers.GetFloat64(t.Bytes[start+flatbuffers.UOffsetT(8*i):])
+	}
+	return out
+}
+
+func (t fbTable) uint32s(n int) []uint32 {
+	start, count := t.vector(n)
+	out := make([]uint32, count)
+	for i := range out {
+		out[i] = flatbuffers.GetUint32(t.Bytes[start+flatbuffers.UOffsetT(4*i):])
+	}
+	return out
+}
+
+func (t fbTable) table(n int) (fbTable, bool) {
+	p := t.field(n)
+	if p == 0 {
+		return fbTable{}, false
+	}
+	return fbTable{flatbuffers.Table{Bytes: t.Bytes, Pos: t.Indirect(p)}}, true
+}
+
+func (t fbTable) tables(n int) []fbTable {
+	start, count := t.vector(n)
+	out := make([]fbTable, count)
+	for i := range out {
+		elem := start + flatbuffers.UOffsetT(4*i)
+		out[i] = fbTable{flatbuffers.Table{Bytes: t.Bytes, Pos: t.Indirect(elem)}}
+	}
+	return out
+}
@@ -0,0 +1,252 @@
+package gotiler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/paulmach/orb/encoding/wkb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// parquetBatchSize bounds the rows decoded at once from a row group.
+const parquetBatchSize = 8192
+
+// geoMetadata is the GeoParquet "geo" file metadata.
+// Spec: https://geoparquet.org/releases/v1.1.0/
+type geoMetadata struct {
+	Version       string                       `json:"version"`
+	PrimaryColumn string                       `json:"primary_column"`
+	Columns       map[string]geoColumnMetadata `json:"columns"`
+}
+
+type geoColumnMetadata struct {
+	Encoding string `json:"encoding"`
+	Covering *struct {
+		BBox struct {
+			XMin []string `json:"xmin"`
+		} `json:"bbox"`
+	} `json:"covering"`
+}
+
+// geoparquetReader streams features from a GeoParquet file one row group
+// at a time. The primary geometry column must be WKB encoded. A bbox
+// covering column only indexes the geometry, so it is not read and does
+// not become a property; every other column does.
+type geoparquetReader struct {
+	f   *os.File
+	pf  *file.Reader
+	fr  *pqarrow.FileReader
+	ctx context.Context
+
+	geometry string // primary geometry column name
+	leaves   []int  // leaf columns to read
+	rowGroup int
+
+	rr  pqarrow.RecordReader
+	rec arrow.Record
+	row int
+}
+
+func openGeoParquet(f *os.File) (*geoparquetReader, error) {
+	pf, err := file.NewParquetReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("opening parquet: %w", err)
+	}
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{BatchSize: parquetBatchSize}, memory.DefaultAllocator)
+	if err != nil {
+		pf.Close()
+		return nil, fmt.Errorf("opening parquet: %w", err)
+	}
+
+	geometry, covering, err := geoColumns(pf)
+	if err != nil {
+		pf.Close()
+		return nil, err
+	}
+
+	r := &geoparquetReader{f: f, pf: pf, fr: fr, ctx: context.Background(), geometry: geometry}
+	schema := pf.MetaData().Schema
+	found := false
+	for i := 0; i < schema.NumColumns(); i++ {
+		top := schema.Column(i).ColumnPath()[0]
+		if top == covering {
+			continue
+		}
+		found = found || top == geometry
+		r.leaves = append(r.leaves, i)
+	}
+	if !found {
+		pf.Close()
+		return nil, fmt.Errorf("geoparquet geometry column %q not found", geometry)
+	}
+	return r, nil
+}
+
+// geoColumns returns the primary geometry column and its bbox covering
+// column, if any. Files without "geo" metadata fall back to a column
+// named "geometry" holding WKB.
+func geoColumns(pf *file.Reader) (geometry, covering string, err error) {
+	value := pf.MetaData().KeyValueMetadata().FindValue("geo")
+	if value == nil {
+		return "geometry", "", nil
+	}
+
+	var meta geoMetadata
+	if err := json.Unmarshal([]byte(*value), &meta); err != nil {
+		return "", "", fmt.Errorf("parsing geoparquet metadata: %w", err)
+	}
+	geometry = meta.PrimaryColumn
+	if geometry == "" {
+		geometry = "geometry"
+	}
+	col := meta.Columns[geometry]
+	if col.Encoding != "" && col.Encoding != "WKB" {
+		return "", "", fmt.Errorf("unsupported geoparquet geometry encoding %q (only WKB)", col.Encoding)
+	}
+	if col.Covering != nil && len(col.Covering.BBox.XMin) > 0 {
+		covering = col.Covering.BBox.XMin[0]
+	}
+	return geometry, covering, nil
+}
+
+// Next returns the feature for the next row. Rows with a null geometry
+// are returned without one and skipped by the tiler.
+func (r *geoparquetReader) Next() (*geojson.Feature, error) {
+	for r.rec == nil || r.row >= int(r.rec.NumRows()) {
+		if err := r.nextBatch(); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { r.row++ }()
+
+	f := geojson.NewFeature(nil)
+	for i, col := range r.rec.Columns() {
+		name := r.rec.ColumnName(i)
+		if name != r.geometry {
+			if v := propertyValue(col, r.row); v != nil {
+				f.Properties[name] = v
+			}
+			continue
+		}
+		if col.IsNull(r.row) {
+			continue
+		}
+		var data []byte
+		switch a := col.(type) {
+		case *array.Binary:
+			data = a.Value(r.row)
+		case *array.LargeBinary:
+			data = a.Value(r.row)
+		default:
+			return nil, fmt.Errorf("geoparquet geometry column %q is %s, not WKB binary", name, col.DataType())
+		}
+		geom, err := wkb.Unmarshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding geoparquet row geometry: %w", err)
+		}
+		f.Geometry = geom
+	}
+	return f, nil
+}
+
+// nextBatch advances to the next record batch, opening the next row
+// group's reader when the current one is exhausted.
+func (r *geoparquetReader) nextBatch() error {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	r.row = 0
+
+	for {
+		if r.rr == nil {
+			if r.rowGroup >= r.pf.NumRowGroups() {
+				return io.EOF
+			}
+			rr, err := r.fr.GetRecordReader(r.ctx, r.leaves, []int{r.rowGroup})
+			if err != nil {
+				return fmt.Errorf("reading parquet row group %d: %w", r.rowGroup, err)
+			}
+			r.rr = rr
+			r.rowGroup++
+		}
+		if r.rr.Next() {
+			r.rec = r.rr.Record()
+			r.rec.Retain()
+			return nil
+		}
+		if err := r.rr.Err(); err != nil && err != io.EOF {
+			return fmt.Errorf("reading parquet row group %d: %w", r.rowGroup-1, err)
+		}
+		r.rr.Release()
+		r.rr = nil
+	}
+}
+
+// Close releases buffered batches and closes the file.
+func (r *geoparquetReader) Close() error {
+	if r.rec != nil {
+		r.rec.Release()
+	}
+	if r.rr != nil {
+		r.rr.Release()
+	}
+	r.pf.Close()
+	return r.f.Close()
+}
+
+// propertyValue converts a column value to the JSON types GeoJSON
+// properties use: numbers become float64, and nested or temporal values
+// are encoded as JSON strings.
+func propertyValue(col arrow.Array, i int) any {
+	if col.IsNull(i) {
+		return nil
+	}
+	switch a := col.(type) {
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.String:
+		return a.Value(i)
+	case *array.LargeString:
+		return a.Value(i)
+	}
+
+	v := col.GetOneForMarshal(i)
+	switch n := v.(type) {
+	case int8:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint8:
+		return float64(n)
+	case uint16:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	case string:
+		return n
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return col.ValueStr(i)
+	}
+	return string(b)
+}
@@ -20,22 +20,35 @@
 package gotiler
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
+	"sync/atomic"
 
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/encoding/mvt"
 	"github.com/paulmach/orb/geojson"
 	"github.com/paulmach/orb/maptile"
-	"github.com/paulmach/orb/planar"
 	"github.com/paulmach/orb/simplify"
 
 	"github.com/joeblew999/plat-geo/internal/tiler"
 	"github.com/joeblew999/plat-geo/internal/pmtiles"
 )
 
+const (
+	// progressInterval is how many tiles are written between progress
+	// reports within a zoom.
+	progressInterval = 1000
+	// cancelCheckInterval is how many features are read between checks
+	// for cancellation.
+	cancelCheckInterval = 4096
+)
+
 // GoTiler implements tiler.Tiler using pure Go libraries.
 type GoTiler struct{}
 
@@ -54,241 +67,535 @@ func (g *GoTiler) Available() bool {
 	return true
 }
 
-// Tile converts GeoJSON to PMTiles using pure Go.
-func (g *GoTiler) Tile(inputPath, outputPath string, config tiler.TileConfig) error {
-	// Read GeoJSON
-	data, err := os.ReadFile(inputPath)
+// tileOptions are the archive-wide settings used while encoding tiles,
+// resolved from a tiler.TileConfig.
+type tileOptions struct {
+	compression     pmtiles.Compression
+	minZoom         int // union of the layers' zoom ranges
+	maxZoom         int
+	maxTileBytes    int // 0 = unlimited
+	maxTileFeatures int // 0 = unlimited
+	dropDensest     bool
+	layers          []*layerOptions
+}
+
+// layerOptions are the settings of one vector layer and its source.
+type layerOptions struct {
+	name       string
+	path       string
+	minZoom    int
+	maxZoom    int
+	reduceRate float64 // points kept per zoom step below maxZoom is 1/reduceRate
+
+	clusterDistance  float64 // tile extent units, 0 = no clustering
+	clusterMaxZoom   int
+	clusterAggregate []string
+
+	attributes *attributeFilter // nil keeps properties unchanged
+
+	// Layers occupy consecutive ranges of feature locations, [start, end).
+	// A layer read in place maps its source's locations into its range.
+	start, end uint64
+	inPlace    inPlaceReader
+
+	// An indexed layer records no features in pass 1, only a marker at
+	// start for each tile it touches, and each feature's min zoom by
+	// position so pass 2 can thin the features its searches find.
+	index indexedReader
+	zooms []uint8
+}
+
+// hiddenZoom is the min zoom of an indexed layer's features that appear
+// in no tile.
+const hiddenZoom = math.MaxUint8
+
+// maxMarkedTiles bounds the tiles an indexed layer remembers marking, so
+// neighbouring features don't mark the same tile again and again.
+const maxMarkedTiles = 1 << 16
+
+// read loads one of the layer's features.
+func (l *layerOptions) read(store *featureStore, ref featureRef) (*geojson.Feature, error) {
+	if l.inPlace == nil {
+		return store.read(ref)
+	}
+	ref.offset -= l.start
+	f, err := l.inPlace.readAt(ref)
 	if err != nil {
-		return fmt.Errorf("reading geojson: %w", err)
+		return nil, err
 	}
+	// Features read in place are raw, so filter them as pass 1 did
+	l.attributes.apply(f.Properties)
+	return f, nil
+}
+
+// search reads the features of an indexed layer that intersect a tile
+// and are visible at its zoom, in source order.
+func (l *layerOptions) search(tile maptile.Tile, fn func(*geojson.Feature) error) error {
+	return l.index.search(tile.Bound(), func(i, offset uint64) error {
+		if i >= uint64(len(l.zooms)) || int(l.zooms[i]) > int(tile.Z) {
+			return nil
+		}
+		f, err := l.index.readFeatureAt(offset)
+		if err != nil {
+			return err
+		}
+		l.attributes.apply(f.Properties)
+		return fn(f)
+	})
+}
 
-	fc, err := geojson.UnmarshalFeatureCollection(data)
+// newTileOptions applies gotiler's defaults to config and layers: zooms
+// are clamped to 0-14, and tile limits and the point reduce rate follow
+// tippecanoe.
+func newTileOptions(config tiler.TileConfig, layers []tiler.LayerInput) (*tileOptions, error) {
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("no layers to tile")
+	}
+	compression, err := pmtiles.ParseCompression(config.Compression)
 	if err != nil {
-		return fmt.Errorf("parsing geojson: %w", err)
+		return nil, err
 	}
 
-	// Determine zoom range
-	minZoom := config.MinZoom
-	maxZoom := config.MaxZoom
-	if minZoom < 0 {
-		minZoom = 0
+	opts := &tileOptions{
+		compression:     compression,
+		maxTileBytes:    defaultMaxTileBytes,
+		maxTileFeatures: defaultMaxTileFeatures,
+		dropDensest:     config.DropDensest,
+	}
+	if config.NoTileSizeLimit {
+		opts.maxTileBytes = 0
 	}
-	if maxZoom < 0 || maxZoom > 14 {
-		maxZoom = 14
+	if config.NoFeatureLimit {
+		opts.maxTileFeatures = 0
 	}
 
-	// Generate tiles for each zoom level
-	tiles := make(map[maptile.Tile][]byte)
+	names := make(map[string]bool, len(layers))
+	for i, input := range layers {
+		l, err := newLayerOptions(input)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: %w", input.Config.Layer, err)
+		}
+		if names[l.name] {
+			return nil, fmt.Errorf("duplicate layer %q", l.name)
+		}
+		names[l.name] = true
 
-	for z := minZoom; z <= maxZoom; z++ {
-		zoomTiles := g.generateZoomLevel(fc, uint32(z), config.Layer)
-		for tile, data := range zoomTiles {
-			tiles[tile] = data
+		if i == 0 || l.minZoom < opts.minZoom {
+			opts.minZoom = l.minZoom
+		}
+		if i == 0 || l.maxZoom > opts.maxZoom {
+			opts.maxZoom = l.maxZoom
 		}
+		opts.layers = append(opts.layers, l)
 	}
-
-	// Write PMTiles
-	return writePMTiles(outputPath, tiles, config)
+	return opts, nil
 }
 
-// generateZoomLevel creates MVT tiles for a specific zoom level.
-func (g *GoTiler) generateZoomLevel(fc *geojson.FeatureCollection, zoom uint32, layerName string) map[maptile.Tile][]byte {
-	result := make(map[maptile.Tile][]byte)
-
-	// Group features by tile
-	tileFeatures := make(map[maptile.Tile][]*geojson.Feature)
-
-	for _, f := range fc.Features {
-		// Get all tiles that intersect this feature's bounds
-		bounds := f.Geometry.Bound()
-		tiles := tilesInBounds(bounds, zoom)
-
-		for _, tile := range tiles {
-			tileFeatures[tile] = append(tileFeatures[tile], f)
-		}
+func newLayerOptions(input tiler.LayerInput) (*layerOptions, error) {
+	config := input.Config
+	attributes, err := newAttributeFilter(config)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate MVT for each tile
-	for tile, features := range tileFeatures {
-		mvtData := g.createMVT(tile, features, layerName)
-		if len(mvtData) > 0 {
-			result[tile] = mvtData
+	l := &layerOptions{
+		name:       config.Layer,
+		path:       input.Path,
+		minZoom:    config.MinZoom,
+		maxZoom:    config.MaxZoom,
+		reduceRate: defaultReduceRate,
+		attributes: attributes,
+	}
+	if l.minZoom < 0 {
+		l.minZoom = 0
+	}
+	if l.maxZoom < 0 || l.maxZoom > 14 {
+		l.maxZoom = 14
+	}
+	if l.minZoom > l.maxZoom {
+		return nil, fmt.Errorf("min zoom %d is above max zoom %d", l.minZoom, l.maxZoom)
+	}
+	if config.ReduceRate > 0 {
+		l.reduceRate = float64(config.ReduceRate)
+	}
+	if config.ClusterDistance > 0 {
+		l.clusterDistance = clusterDistance(config.ClusterDistance)
+		l.clusterMaxZoom = config.ClusterMaxZoom
+		if l.clusterMaxZoom <= 0 {
+			l.clusterMaxZoom = l.maxZoom - 1
 		}
+		l.clusterAggregate = config.ClusterAggregate
+		// Clusters must count every point, so they replace thinning
+		l.reduceRate = 1
 	}
-
-	return result
+	return l, nil
 }
 
-// createMVT creates an MVT tile from features.
-func (g *GoTiler) createMVT(tile maptile.Tile, features []*geojson.Feature, layerName string) []byte {
-	// Create a FeatureCollection for this tile
-	fc := geojson.NewFeatureCollection()
-	tileBound := tile.Bound()
-
-	for _, f := range features {
-		// Check if geometry truly intersects the tile (not just bounding boxes)
-		if !geometryIntersectsTile(f.Geometry, tileBound) {
-			continue
-		}
+// layerOf returns the layer a stored feature belongs to.
+func (o *tileOptions) layerOf(ref featureRef) int {
+	return sort.Search(len(o.layers), func(i int) bool { return ref.offset < o.layers[i].end })
+}
 
-		// Deep clone the geometry - MVT mutates geometry in place during Clip/Project,
-		// so we must use a copy to avoid corrupting the original for other tiles
-		clonedGeom := cloneGeometry(f.Geometry)
-		if clonedGeom == nil {
-			continue
-		}
+// Tile converts GeoJSON or GeoJSONSeq to PMTiles using pure Go.
+//
+// Input is streamed rather than loaded: features are spooled to a temp
+// directory next to the output, their tile assignments are sorted by tile
+// ID on disk once they exceed config.MemoryBudget, and tiles are encoded
+// in ID order straight into the archive's tile data section.
+//
+// Like tippecanoe, points are thinned below the max zoom by
+// config.ReduceRate (default 2.5) and tiles are limited to 500 KB and
+// 200,000 features unless NoTileSizeLimit or NoFeatureLimit is set. A tile
+// over a limit fails the run unless DropDensest is set, in which case the
+// most crowded features are dropped until it fits.
+//
+// Properties are filtered and coerced per config.Include, Exclude and
+// AttributeTypes as features are read, and AttributeMinZoom strips them
+// from shallower tiles.
+//
+// With config.ClusterDistance set, points are merged into cluster features
+// at zooms up to config.ClusterMaxZoom instead of being thinned.
+//
+// Progress is reported as tiles are written, with the percentage
+// estimated from the tile assignments encoded so far.
+func (g *GoTiler) Tile(ctx context.Context, inputPath, outputPath string, config tiler.TileConfig, progress tiler.ProgressFunc) error {
+	return g.TileLayers(ctx, []tiler.LayerInput{{Path: inputPath, Config: config}}, outputPath, config, progress)
+}
 
-		clone := geojson.NewFeature(clonedGeom)
-		for k, v := range f.Properties {
-			clone.Properties[k] = v
-		}
-		fc.Append(clone)
+// TileLayers writes several sources into one archive, one vector layer
+// each, as Tile does for a single source. The sources are spooled one
+// after another and share the tile assignment sort, so every tile is
+// encoded once with all of its layers, and tile limits apply to the
+// layers together.
+func (g *GoTiler) TileLayers(ctx context.Context, layers []tiler.LayerInput, outputPath string, config tiler.TileConfig, progress tiler.ProgressFunc) error {
+	opts, err := newTileOptions(config, layers)
+	if err != nil {
+		return err
 	}
 
-	// Skip empty tiles
-	if len(fc.Features) == 0 {
-		return nil
+	budget := config.MemoryBudget
+	if budget <= 0 {
+		budget = defaultMemoryBudget
 	}
 
-	// Create layer from FeatureCollection
-	layer := mvt.NewLayer(layerName, fc)
+	tmpDir, err := os.MkdirTemp(filepath.Dir(outputPath), ".gotiler-*")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
 
-	// Simplify based on zoom level - less detail at lower zooms
-	epsilon := simplifyEpsilon(tile.Z)
-	if epsilon > 0 {
-		layer.Simplify(simplify.DouglasPeucker(epsilon))
+	store, err := newFeatureStore(tmpDir)
+	if err != nil {
+		return fmt.Errorf("creating feature spool: %w", err)
 	}
+	defer store.Close()
+
+	// Pass 1: spool features and assign them to tiles, layer by layer
+	sorter := newTileSorter(tmpDir, budget)
+	stats := make([]*layerStats, len(opts.layers))
+	for i, l := range opts.layers {
+		stats[i] = newLayerStats(l.name, l.minZoom, l.maxZoom)
+		// Registered first so a source read in place is closed even
+		// when assigning its features fails
+		defer func() {
+			if l.inPlace != nil {
+				l.inPlace.Close()
+			}
+		}()
+		if err := g.assignLayer(ctx, l, store, sorter, stats[i]); err != nil {
+			if len(opts.layers) > 1 {
+				return fmt.Errorf("layer %q: %w", l.name, err)
+			}
+			return err
+		}
 
-	// Clip to tile bounds - this clips in world coordinates before projection
-	layer.Clip(tileBound)
+		if l.clusterDistance > 0 {
+			stats[i].declare("cluster", "boolean")
+			stats[i].declare("point_count", "number")
+			for _, name := range l.clusterAggregate {
+				for _, suffix := range []string{"_sum", "_min", "_max"} {
+					stats[i].declare(name+suffix, "number")
+				}
+			}
+		}
+	}
+	if err := store.finish(); err != nil {
+		return fmt.Errorf("spooling features: %w", err)
+	}
 
-	// Project to tile coordinates (0-4096 extent)
-	layer.ProjectToTile(tile)
+	// Pass 2: encode tiles concurrently, adding them in tile ID order
+	archive, err := newArchiveWriter(tmpDir)
+	if err != nil {
+		return fmt.Errorf("creating tile spool: %w", err)
+	}
+	defer archive.Close()
 
-	// Remove empty features after clipping/projection
-	// Use smaller threshold to keep more geometry
-	layer.RemoveEmpty(0.5, 0.5)
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	var encoded atomic.Int64 // tile assignments encoded, for progress
+	encode := func(tileID uint64, refs []featureRef) ([]byte, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		defer encoded.Add(int64(len(refs)))
+		z, x, y := pmtiles.IDToZxy(tileID)
+		return g.createMVT(maptile.New(x, y, maptile.Zoom(z)), store, refs, opts)
+	}
+	add := archive.add
+	if progress != nil {
+		p := tiler.Progress{Zoom: -1}
+		add = func(tileID uint64, data []byte) error {
+			if err := archive.add(tileID, data); err != nil {
+				return err
+			}
+			z, _, _ := pmtiles.IDToZxy(tileID)
+			p.TilesDone++
+			p.BytesWritten = int64(archive.offset)
+			if int(z) != p.Zoom || p.TilesDone%progressInterval == 0 {
+				p.Zoom = int(z)
+				p.Percent = 100 * float64(encoded.Load()) / float64(max(sorter.count, 1))
+				progress(p)
+			}
+			return nil
+		}
+	}
+	if err := encodeTiles(sorter, workers, encode, add); err != nil {
+		return err
+	}
 
-	// Skip if all features were removed
-	if len(layer.Features) == 0 {
-		return nil
+	// Write PMTiles with the zoom range actually generated
+	config.MinZoom = opts.minZoom
+	config.MaxZoom = opts.maxZoom
+	if err := writePMTiles(outputPath, archive, stats, config); err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(tiler.Progress{Zoom: opts.maxZoom, TilesDone: int64(archive.addressed), BytesWritten: int64(archive.offset), Percent: 100})
 	}
+	return nil
+}
 
-	// Encode to protobuf
-	layers := mvt.Layers{layer}
-	data, err := mvt.MarshalGzipped(layers)
+// assignLayer opens a layer's source and assigns its features to tiles.
+// Sources that can be read in place stay open for pass 2; others are
+// spooled and closed.
+func (g *GoTiler) assignLayer(ctx context.Context, layer *layerOptions, store *featureStore, sorter *tileSorter, stats *layerStats) error {
+	src, err := openSource(layer.path)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	return data
+	layer.start = store.offset
+	if r, ok := src.(inPlaceReader); ok {
+		if layer.start, err = store.reserve(r.extent()); err != nil {
+			src.Close()
+			return fmt.Errorf("spooling features: %w", err)
+		}
+		layer.inPlace = r
+		if ir, ok := r.(indexedReader); ok && ir.indexed() {
+			layer.index = ir
+		}
+	} else {
+		defer src.Close()
+	}
+
+	err = g.assignFeatures(ctx, src, store, sorter, stats, layer)
+	layer.end = store.offset
+	return err
 }
 
-// geometryIntersectsTile checks if a geometry truly intersects a tile.
-// This is more thorough than just checking bounding box intersection.
-func geometryIntersectsTile(geom orb.Geometry, tileBound orb.Bound) bool {
-	// First check bounding box intersection (quick rejection)
-	if !geom.Bound().Intersects(tileBound) {
-		return false
-	}
-
-	// Create tile polygon for containment checks
-	tilePoly := orb.Polygon{orb.Ring{
-		tileBound.Min,
-		orb.Point{tileBound.Max[0], tileBound.Min[1]},
-		tileBound.Max,
-		orb.Point{tileBound.Min[0], tileBound.Max[1]},
-		tileBound.Min,
-	}}
-
-	switch g := geom.(type) {
-	case orb.Point:
-		return tileBound.Contains(g)
-
-	case orb.MultiPoint:
-		for _, p := range g {
-			if tileBound.Contains(p) {
-				return true
+// assignFeatures streams a layer's source, spooling each feature unless
+// it is read in place, and recording every tile it touches at each zoom
+// from which it is visible. An indexed layer only marks the tiles.
+func (g *GoTiler) assignFeatures(ctx context.Context, src featureReader, store *featureStore, sorter *tileSorter, stats *layerStats, layer *layerOptions) error {
+	thin := &pointThinning{rate: layer.reduceRate, baseZoom: layer.maxZoom}
+	marked := make(map[uint64]bool)
+
+	for n := 0; ; n++ {
+		if n%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
 		}
-		return false
-
-	case orb.Polygon:
-		// Check if any polygon vertex is in the tile
-		for _, ring := range g {
-			for _, p := range ring {
-				if tileBound.Contains(p) {
-					return true
-				}
-			}
+		f, err := src.Next()
+		if err == io.EOF {
+			return nil
 		}
-		// Check if any tile corner is in the polygon
-		for _, p := range tilePoly[0][:4] {
-			if planar.PolygonContains(g, p) {
-				return true
-			}
+		if err != nil {
+			return err
 		}
-		// Check if tile center is in polygon (handles case where polygon fully contains tile)
-		if planar.PolygonContains(g, tileBound.Center()) {
-			return true
+		if f.Geometry == nil {
+			if layer.index != nil {
+				layer.zooms = append(layer.zooms, hiddenZoom)
+			}
+			continue
 		}
-		return false
 
-	case orb.MultiPolygon:
-		for _, poly := range g {
-			if geometryIntersectsTile(poly, tileBound) {
-				return true
+		layer.attributes.apply(f.Properties)
+		stats.add(f)
+
+		var ref featureRef
+		switch {
+		case layer.index != nil:
+			ref = featureRef{offset: layer.start}
+		case layer.inPlace != nil:
+			ref = layer.inPlace.location()
+			ref.offset += layer.start
+		default:
+			if ref, err = store.append(f); err != nil {
+				return fmt.Errorf("spooling feature: %w", err)
 			}
 		}
-		return false
 
-	case orb.LineString:
-		// Check if any vertex is in tile
-		for _, p := range g {
-			if tileBound.Contains(p) {
-				return true
+		minZoom := layer.minZoom
+		if isPoint(f.Geometry) {
+			minZoom = thin.minZoom(layer.minZoom)
+		}
+		if layer.index != nil {
+			layer.zooms = append(layer.zooms, uint8(minZoom))
+		}
+		err = assignTiles(f.Geometry, minZoom, layer.maxZoom, func(t maptile.Tile) error {
+			id := pmtiles.ZxyToID(uint8(t.Z), t.X, t.Y)
+			if layer.index != nil {
+				if marked[id] {
+					return nil
+				}
+				if len(marked) >= maxMarkedTiles {
+					clear(marked)
+				}
+				marked[id] = true
 			}
+			return sorter.add(tileRef{tileID: id, feature: ref})
+		})
+		if err != nil {
+			return fmt.Errorf("sorting tiles: %w", err)
+		}
+	}
+}
+
+// createMVT creates an MVT tile from the stored features at refs.
+// Features are decoded, clipped and projected one at a time, so only the
+// projected tile geometry accumulates in memory. Refs arrive in store
+// order, so each layer's features are contiguous; an indexed layer's refs
+// are markers, and its features are searched for once.
+func (g *GoTiler) createMVT(tile maptile.Tile, store *featureStore, refs []featureRef, opts *tileOptions) ([]byte, error) {
+	tileBound := tile.Bound()
+	epsilon := simplifyEpsilon(tile.Z)
+
+	var layers mvt.Layers
+	var owners []*layerOptions
+	var current *layerOptions
+	var layer *mvt.Layer
+	add := func(f *geojson.Feature) {
+		current.attributes.forTile(f.Properties, int(tile.Z))
+
+		fl := mvt.NewLayer(current.name, &geojson.FeatureCollection{Features: []*geojson.Feature{f}})
+
+		// Simplify based on zoom level - less detail at lower zooms
+		if epsilon > 0 {
+			fl.Simplify(simplify.DouglasPeucker(epsilon))
+		}
+
+		// Clip to tile bounds - this clips in world coordinates before projection
+		fl.Clip(tileBound)
+
+		// Project to tile coordinates (0-4096 extent)
+		fl.ProjectToTile(tile)
+
+		// Remove empty features after clipping/projection
+		// Use smaller threshold to keep more geometry
+		fl.RemoveEmpty(0.5, 0.5)
+
+		layer.Features = append(layer.Features, fl.Features...)
+	}
+	for _, ref := range refs {
+		l := opts.layers[opts.layerOf(ref)]
+		if l == current && l.index != nil {
+			continue
+		}
+		if l != current {
+			current = l
+			layer = mvt.NewLayer(l.name, geojson.NewFeatureCollection())
+			layers = append(layers, layer)
+			owners = append(owners, l)
 		}
-		// Check if line crosses tile (simplified check)
-		return true // If bounding boxes intersect, likely crosses
 
-	case orb.MultiLineString:
-		for _, ls := range g {
-			if geometryIntersectsTile(ls, tileBound) {
-				return true
+		if l.index != nil {
+			err := l.search(tile, func(f *geojson.Feature) error {
+				add(f)
+				return nil
+			})
+			if err != nil {
+				return nil, err
 			}
+			continue
 		}
-		return false
 
-	default:
-		// For unknown types, trust bounding box check
-		return true
+		// Each read returns a fresh copy, so MVT's in-place
+		// Simplify/Clip/Project can't corrupt geometry for other tiles
+		f, err := current.read(store, ref)
+		if err != nil {
+			return nil, err
+		}
+		add(f)
+	}
+
+	// Cluster points and skip layers whose features were all removed
+	kept := layers[:0]
+	for i, layer := range layers {
+		if len(layer.Features) == 0 {
+			continue
+		}
+		if l := owners[i]; l.clusterDistance > 0 && int(tile.Z) <= l.clusterMaxZoom {
+			layer.Features = clusterPoints(layer.Features, l.clusterDistance, l.clusterAggregate)
+		}
+		kept = append(kept, layer)
+	}
+	if len(kept) == 0 {
+		return nil, nil
 	}
+
+	// Encode to protobuf and compress within the tile limits
+	return encodeLimited(tile, kept, opts)
 }
 
 // tilesInBounds returns all tiles at a zoom level that intersect a bounding box.
 func tilesInBounds(bounds orb.Bound, zoom uint32) []maptile.Tile {
+	minX, minY, maxX, maxY := tileRange(bounds, zoom)
+
+	var tiles []maptile.Tile
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			tiles = append(tiles, maptile.New(x, y, maptile.Zoom(zoom)))
+		}
+	}
+	return tiles
+}
+
+// tileCount returns how many tiles at zoom cover bounds, without listing
+// them.
+func tileCount(bounds orb.Bound, zoom uint32) uint64 {
+	minX, minY, maxX, maxY := tileRange(bounds, zoom)
+	return uint64(maxX-minX+1) * uint64(maxY-minY+1)
+}
+
+// tileRange returns the tile columns and rows at zoom that cover bounds.
+func tileRange(bounds orb.Bound, zoom uint32) (minX, minY, maxX, maxY uint32) {
 	// Get corner tiles
 	minTile := maptile.At(bounds.Min, maptile.Zoom(zoom))
 	maxTile := maptile.At(bounds.Max, maptile.Zoom(zoom))
 
 	// Ensure min/max are ordered correctly
-	minX, maxX := minTile.X, maxTile.X
+	minX, maxX = minTile.X, maxTile.X
 	if minX > maxX {
 		minX, maxX = maxX, minX
 	}
-	minY, maxY := minTile.Y, maxTile.Y
+	minY, maxY = minTile.Y, maxTile.Y
 	if minY > maxY {
 		minY, maxY = maxY, minY
 	}
-
-	var tiles []maptile.Tile
-	for x := minX; x <= maxX; x++ {
-		for y := minY; y <= maxY; y++ {
-			tiles = append(tiles, maptile.New(x, y, maptile.Zoom(zoom)))
-		}
-	}
-	return tiles
+	return minX, minY, maxX, maxY
 }
 
 // simplifyEpsilon returns the simplification tolerance for a zoom level.
@@ -311,193 +618,5 @@ func simplifyEpsilon(zoom maptile.Zoom) float64 {
 	}
 }
 
-// cloneGeometry creates a deep copy of geometry to avoid mutation by MVT.
-// MVT's Clip and ProjectToTile methods mutate geometry in place, which would
-// corrupt the original geometry needed for generating other tiles.
-func cloneGeometry(g orb.Geometry) orb.Geometry {
-	switch geom := g.(type) {
-	case orb.Point:
-		return orb.Point{geom[0], geom[1]}
-
-	case orb.MultiPoint:
-		clone := make(orb.MultiPoint, len(geom))
-		for i, p := range geom {
-			clone[i] = orb.Point{p[0], p[1]}
-		}
-		return clone
-
-	case orb.LineString:
-		clone := make(orb.LineString, len(geom))
-		for i, p := range geom {
-			clone[i] = orb.Point{p[0], p[1]}
-		}
-		return clone
-
-	case orb.MultiLineString:
-		clone := make(orb.MultiLineString, len(geom))
-		for i, ls := range geom {
-			clone[i] = make(orb.LineString, len(ls))
-			for j, p := range ls {
-				clone[i][j] = orb.Point{p[0], p[1]}
-			}
-		}
-		return clone
-
-	case orb.Ring:
-		clone := make(orb.Ring, len(geom))
-		for i, p := range geom {
-			clone[i] = orb.Point{p[0], p[1]}
-		}
-		return clone
-
-	case orb.Polygon:
-		clone := make(orb.Polygon, len(geom))
-		for i, ring := range geom {
-			clone[i] = make(orb.Ring, len(ring))
-			for j, p := range ring {
-				clone[i][j] = orb.Point{p[0], p[1]}
-			}
-		}
-		return clone
-
-	case orb.MultiPolygon:
-		clone := make(orb.MultiPolygon, len(geom))
-		for i, poly := range geom {
-			clone[i] = make(orb.Polygon, len(poly))
-			for j, ring := range poly {
-				clone[i][j] = make(orb.Ring, len(ring))
-				for k, p := range ring {
-					clone[i][j][k] = orb.Point{p[0], p[1]}
-				}
-			}
-		}
-		return clone
-
-	default:
-		return nil
-	}
-}
-
 // Ensure GoTiler implements Tiler.
 var _ tiler.Tiler = (*GoTiler)(nil)
-
-// writePMTiles writes tiles to a PMTiles file using the official go-pmtiles library.
-// PMTiles v3 format: https://github.com/protomaps/PMTiles/blob/main/spec/v3/spec.md
-func writePMTiles(path string, tiles map[maptile.Tile][]byte, config tiler.TileConfig) error {
-	if len(tiles) == 0 {
-		return fmt.Errorf("no tiles to write")
-	}
-
-	// Convert tiles to PMTiles entries, sorted by tile ID
-	type tileEntry struct {
-		id   uint64
-		data []byte
-	}
-	var tileEntries []tileEntry
-
-	for t, data := range tiles {
-		// Use pmtiles ZxyToID for proper tile ID encoding
-		id := pmtiles.ZxyToID(uint8(t.Z), uint32(t.X), uint32(t.Y))
-		tileEntries = append(tileEntries, tileEntry{id: id, data: data})
-	}
-
-	// Sort by tile ID for clustered output
-	sort.Slice(tileEntries, func(i, j int) bool {
-		return tileEntries[i].id < tileEntries[j].id
-	})
-
-	// Build directory entries and collect tile data
-	var entries []pmtiles.EntryV3
-	var tileData bytes.Buffer
-	currentOffset := uint64(0)
-
-	for _, te := range tileEntries {
-		entries = append(entries, pmtiles.EntryV3{
-			TileID:    te.id,
-			Offset:    currentOffset,
-			Length:    uint32(len(te.data)),
-			RunLength: 1,
-		})
-		tileData.Write(te.data)
-		currentOffset += uint64(len(te.data))
-	}
-
-	// Build metadata JSON
-	metadata := map[string]any{
-		"name":        config.Layer,
-		"format":      "pbf",
-		"compression": "gzip",
-		"minzoom":     config.MinZoom,
-		"maxzoom":     config.MaxZoom,
-	}
-	metadataBytes, err := pmtiles.SerializeMetadata(metadata, pmtiles.Gzip)
-	if err != nil {
-		return fmt.Errorf("serializing metadata: %w", err)
-	}
-
-	// Serialize the root directory with gzip compression
-	rootDirBytes := pmtiles.SerializeEntries(entries, pmtiles.Gzip)
-
-	// Calculate offsets
-	headerSize := uint64(pmtiles.HeaderV3LenBytes)
-	rootDirOffset := headerSize
-	rootDirLen := uint64(len(rootDirBytes))
-	metadataOffset := rootDirOffset + rootDirLen
-	metadataLen := uint64(len(metadataBytes))
-	tileDataOffset := metadataOffset + metadataLen
-	tileDataLen := uint64(tileData.Len())
-
-	// Build header
-	header := pmtiles.HeaderV3{
-		SpecVersion:         3,
-		RootOffset:          rootDirOffset,
-		RootLength:          rootDirLen,
-		MetadataOffset:      metadataOffset,
-		MetadataLength:      metadataLen,
-		LeafDirectoryOffset: 0, // No leaf directories for small files
-		LeafDirectoryLength: 0,
-		TileDataOffset:      tileDataOffset,
-		TileDataLength:      tileDataLen,
-		AddressedTilesCount: uint64(len(entries)),
-		TileEntriesCount:    uint64(len(entries)),
-		TileContentsCount:   uint64(len(entries)), // No deduplication
-		Clustered:           true,
-		InternalCompression: pmtiles.Gzip,
-		TileCompression:     pmtiles.Gzip,
-		TileType:            pmtiles.Mvt,
-		MinZoom:             uint8(config.MinZoom),
-		MaxZoom:             uint8(config.MaxZoom),
-	}
-
-	// Serialize header
-	headerBytes := pmtiles.SerializeHeader(header)
-
-	// Write file
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	// Write header
-	if _, err := f.Write(headerBytes); err != nil {
-		return err
-	}
-
-	// Write root directory
-	if _, err := f.Write(rootDirBytes); err != nil {
-		return err
-	}
-
-	// Write metadata
-	if _, err := f.Write(metadataBytes); err != nil {
-		return err
-	}
-
-	// Write tile data
-	if _, err := f.Write(tileData.Bytes()); err != nil {
-		return err
-	}
-
-	return nil
-}
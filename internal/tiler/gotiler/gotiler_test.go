@@ -0,0 +1,1084 @@
+package gotiler
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/encoding/wkb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+	"github.com/joeblew999/plat-geo/internal/tiler"
+)
+
+// writeTiles writes raw tile contents through archiveWriter in tile ID order.
+func writeTiles(path string, tiles map[maptile.Tile][]byte, config tiler.TileConfig) error {
+	a, err := newArchiveWriter(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ids := make([]uint64, 0, len(tiles))
+	byID := make(map[uint64][]byte, len(tiles))
+	for t, data := range tiles {
+		id := pmtiles.ZxyToID(uint8(t.Z), t.X, t.Y)
+		ids = append(ids, id)
+		byID[id] = data
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if err := a.add(id, byID[id]); err != nil {
+			return err
+		}
+	}
+	return writePMTiles(path, a, nil, config)
+}
+
+func TestWritePMTilesLeafDirectories(t *testing.T) {
+	// 40k distinct tiles at z8 overflow the 16 KiB root directory.
+	tiles := make(map[maptile.Tile][]byte)
+	for x := uint32(0); x < 200; x++ {
+		for y := uint32(0); y < 200; y++ {
+			tiles[maptile.New(x, y, 8)] = []byte(fmt.Sprintf("tile-%d-%d", x, y))
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "big.pmtiles")
+	config := tiler.TileConfig{MinZoom: 8, MaxZoom: 8, Layer: "test"}
+	if err := writeTiles(path, tiles, config); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	h := r.Header()
+	if h.LeafDirectoryLength == 0 {
+		t.Fatal("expected leaf directories")
+	}
+	if h.RootLength > pmtiles.RootDirectoryMaxLen {
+		t.Errorf("root directory is %d bytes, budget %d", h.RootLength, pmtiles.RootDirectoryMaxLen)
+	}
+
+	for _, xy := range [][2]uint32{{0, 0}, {123, 45}, {199, 199}} {
+		data, ok, err := r.Tile(8, xy[0], xy[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := fmt.Sprintf("tile-%d-%d", xy[0], xy[1])
+		if !ok || string(data) != want {
+			t.Errorf("tile 8/%d/%d = %q, %v; want %q", xy[0], xy[1], data, ok, want)
+		}
+	}
+	if _, ok, _ := r.Tile(8, 250, 250); ok {
+		t.Error("tile 8/250/250 should be missing")
+	}
+}
+
+func TestWritePMTilesDeduplication(t *testing.T) {
+	// All 16 z2 tiles share contents except one, which splits the run.
+	tiles := make(map[maptile.Tile][]byte)
+	for x := uint32(0); x < 4; x++ {
+		for y := uint32(0); y < 4; y++ {
+			tiles[maptile.New(x, y, 2)] = []byte("ocean")
+		}
+	}
+	tiles[maptile.New(1, 1, 2)] = []byte("land")
+
+	path := filepath.Join(t.TempDir(), "dedup.pmtiles")
+	if err := writeTiles(path, tiles, tiler.TileConfig{MinZoom: 2, MaxZoom: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	h := r.Header()
+	if h.AddressedTilesCount != 16 {
+		t.Errorf("AddressedTilesCount = %d, want 16", h.AddressedTilesCount)
+	}
+	if h.TileContentsCount != 2 {
+		t.Errorf("TileContentsCount = %d, want 2", h.TileContentsCount)
+	}
+	if h.TileEntriesCount != 3 {
+		t.Errorf("TileEntriesCount = %d, want 3", h.TileEntriesCount)
+	}
+	if h.TileDataLength != uint64(len("ocean")+len("land")) {
+		t.Errorf("TileDataLength = %d, want %d", h.TileDataLength, len("ocean")+len("land"))
+	}
+
+	for x := uint32(0); x < 4; x++ {
+		for y := uint32(0); y < 4; y++ {
+			want := "ocean"
+			if x == 1 && y == 1 {
+				want = "land"
+			}
+			data, ok, err := r.Tile(2, x, y)
+			if err != nil || !ok || string(data) != want {
+				t.Errorf("tile 2/%d/%d = %q, %v, %v; want %q", x, y, data, ok, err, want)
+			}
+		}
+	}
+}
+
+func TestTileHeaderBounds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "points.pmtiles")
+	config := tiler.TileConfig{MinZoom: 0, MaxZoom: 8, Layer: "points"}
+	if err := New().Tile(context.Background(), "../../../testdata/sample-points.geojson", path, config, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	h := r.Header()
+	if h.MinLonE7 >= h.MaxLonE7 || h.MinLatE7 >= h.MaxLatE7 {
+		t.Fatalf("empty bounds: %d,%d %d,%d", h.MinLonE7, h.MinLatE7, h.MaxLonE7, h.MaxLatE7)
+	}
+	if h.CenterLonE7 < h.MinLonE7 || h.CenterLonE7 > h.MaxLonE7 ||
+		h.CenterLatE7 < h.MinLatE7 || h.CenterLatE7 > h.MaxLatE7 {
+		t.Errorf("center %d,%d outside bounds", h.CenterLonE7, h.CenterLatE7)
+	}
+	if h.CenterZoom > h.MaxZoom {
+		t.Errorf("CenterZoom %d > MaxZoom %d", h.CenterZoom, h.MaxZoom)
+	}
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := metadata["bounds"].([]any); !ok {
+		t.Errorf("metadata bounds = %v", metadata["bounds"])
+	}
+	if _, ok := metadata["center"].([]any); !ok {
+		t.Errorf("metadata center = %v", metadata["center"])
+	}
+}
+
+func TestCenterZoom(t *testing.T) {
+	world := orb.Bound{Min: orb.Point{-180, -85}, Max: orb.Point{180, 85}}
+	city := orb.Bound{Min: orb.Point{-122.52, 37.70}, Max: orb.Point{-122.35, 37.82}}
+	tests := []struct {
+		bound            orb.Bound
+		minZoom, maxZoom int
+		want             int
+	}{
+		{world, 0, 14, 0}, // x=180 falls past the last column from z1
+		{world, 3, 14, 3},
+		{city, 0, 14, 11},
+		{city, 0, 8, 8},
+		{orb.Bound{Min: orb.Point{10, 10}, Max: orb.Point{10, 10}}, 0, 14, 14},
+	}
+	for _, tt := range tests {
+		if got := centerZoom(tt.bound, tt.minZoom, tt.maxZoom); got != tt.want {
+			t.Errorf("centerZoom(%v, %d, %d) = %d, want %d", tt.bound, tt.minZoom, tt.maxZoom, got, tt.want)
+		}
+	}
+
+	// Counting the 2^28 world tiles at z14 must not list them
+	if allocs := testing.AllocsPerRun(10, func() { centerZoom(world, 0, 14) }); allocs > 0 {
+		t.Errorf("centerZoom on world bounds made %v allocations", allocs)
+	}
+}
+
+func TestTileVectorLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "points.pmtiles")
+	config := tiler.TileConfig{MinZoom: 0, MaxZoom: 4, Layer: "cities"}
+	if err := New().Tile(context.Background(), "../../../testdata/sample-points.geojson", path, config, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, _ := metadata["vector_layers"].([]any)
+	if len(layers) != 1 {
+		t.Fatalf("vector_layers = %v", metadata["vector_layers"])
+	}
+	layer := layers[0].(map[string]any)
+	if layer["id"] != "cities" {
+		t.Errorf("layer id = %v, want cities", layer["id"])
+	}
+	fields := layer["fields"].(map[string]any)
+	if fields["name"] != "String" || fields["population"] != "Number" {
+		t.Errorf("fields = %v", fields)
+	}
+
+	tilestats, _ := metadata["tilestats"].(map[string]any)
+	statsLayers, _ := tilestats["layers"].([]any)
+	if len(statsLayers) != 1 || statsLayers[0].(map[string]any)["geometry"] != "Point" {
+		t.Errorf("tilestats = %v", metadata["tilestats"])
+	}
+}
+
+func TestTileCompression(t *testing.T) {
+	for _, name := range []string{"none", "gzip", "brotli", "zstd"} {
+		path := filepath.Join(t.TempDir(), name+".pmtiles")
+		config := tiler.TileConfig{MinZoom: 0, MaxZoom: 2, Layer: "region", Compression: name}
+		if err := New().Tile(context.Background(), "../../../testdata/sample-polygon.geojson", path, config, nil); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		r, err := pmtiles.Open(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		h := r.Header()
+		if _, err := r.Metadata(); err != nil {
+			t.Errorf("%s: metadata: %v", name, err)
+		}
+		data, ok, err := r.Tile(0, 0, 0)
+		if err != nil || !ok {
+			t.Fatalf("%s: tile 0/0/0: %v, %v", name, ok, err)
+		}
+		raw, err := pmtiles.Decompress(data, h.TileCompression)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		layers, err := mvt.Unmarshal(raw)
+		if err != nil || len(layers) != 1 || layers[0].Name != "region" {
+			t.Errorf("%s: decoded %v, %v", name, layers, err)
+		}
+		r.Close()
+	}
+}
+
+func TestTileVerifies(t *testing.T) {
+	for _, src := range []string{"sample-points.geojson", "sample-polygon.geojson"} {
+		path := filepath.Join(t.TempDir(), "out.pmtiles")
+		config := tiler.TileConfig{MinZoom: 0, MaxZoom: 10, Layer: "test"}
+		if err := New().Tile(context.Background(), "../../../testdata/"+src, path, config, nil); err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := pmtiles.Verify(f, info.Size())
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		if len(res.Problems) > 0 || res.Tiles == 0 {
+			t.Errorf("%s: verified %d tiles with problems %q", src, res.Tiles, res.Problems)
+		}
+	}
+}
+
+func TestTileDeterministic(t *testing.T) {
+	dir := t.TempDir()
+
+	// 2000 points as a GeoJSON text sequence
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "\x1e{\"type\":\"Feature\",\"geometry\":{\"type\":\"Point\",\"coordinates\":[%f,%f]},\"properties\":{\"i\":%d}}\n",
+			-120+float64(i%50)*0.2, 35+float64(i/50)*0.1, i)
+	}
+	input := filepath.Join(dir, "points.geojsonseq")
+	if err := os.WriteFile(input, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tiny budget forces many sorted runs to be merged; output must not
+	// depend on spilling or on the number of workers.
+	variants := []struct {
+		name    string
+		budget  int64
+		workers int
+	}{
+		{"memory-serial", 0, 1},
+		{"spilled-serial", 1, 1},
+		{"memory-parallel", 0, 8},
+		{"spilled-parallel", 1, 3},
+	}
+	var want []byte
+	for _, v := range variants {
+		out := filepath.Join(dir, v.name+".pmtiles")
+		config := tiler.TileConfig{MinZoom: 0, MaxZoom: 8, Layer: "points", MemoryBudget: v.budget, Workers: v.workers}
+		if err := New().Tile(context.Background(), input, out, config, nil); err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		got, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == nil {
+			want = got
+		} else if string(got) != string(want) {
+			t.Errorf("%s archive differs from %s", v.name, variants[0].name)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".gotiler-") {
+			t.Errorf("temp dir %s not cleaned up", e.Name())
+		}
+	}
+}
+
+func TestAssignTilesDiagonalLine(t *testing.T) {
+	line := orb.LineString{{-170, -80}, {170, 80}}
+	var got []maptile.Tile
+	err := assignTiles(line, 2, 2, func(tile maptile.Tile) error {
+		got = append(got, tile)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) >= 16 {
+		t.Errorf("line assigned to %d of 16 z2 tiles", len(got))
+	}
+	for _, tile := range got {
+		if tile.X == 0 && tile.Y == 0 || tile.X == 3 && tile.Y == 3 {
+			t.Errorf("line assigned to off-diagonal tile %v", tile)
+		}
+	}
+}
+
+func TestRelateTilePolygon(t *testing.T) {
+	// A square around the NW quadrant with a hole over z3 tile 1/3
+	hole := maptile.New(1, 3, 3).Bound()
+	poly := orb.Polygon{
+		{{-181, -1}, {1, -1}, {1, 86}, {-181, 86}, {-181, -1}},
+		{hole.Min, {hole.Min[0], hole.Max[1]}, hole.Max, {hole.Max[0], hole.Min[1]}, hole.Min},
+	}
+
+	tests := []struct {
+		tile maptile.Tile
+		want tileRelation
+	}{
+		{maptile.New(0, 0, 0), intersects},
+		{maptile.New(0, 0, 1), intersects}, // contains the hole
+		{maptile.New(0, 0, 2), covers},
+		{maptile.New(5, 13, 5), disjoint}, // inside the hole
+		{maptile.New(3, 3, 2), disjoint},
+	}
+	for _, tt := range tests {
+		if got := relateTile(poly, tt.tile.Bound()); got != tt.want {
+			t.Errorf("relateTile(%v) = %d, want %d", tt.tile, got, tt.want)
+		}
+	}
+}
+
+func TestPointThinning(t *testing.T) {
+	thin := &pointThinning{rate: 2.5, baseZoom: 8}
+	perZoom := make([]int, 9)
+	for i := 0; i < 10000; i++ {
+		for z := thin.minZoom(0); z <= 8; z++ {
+			perZoom[z]++
+		}
+	}
+	if perZoom[8] != 10000 || perZoom[7] != 4000 || perZoom[6] != 1600 {
+		t.Errorf("points per zoom = %v, want 10000 at z8, 4000 at z7, 1600 at z6", perZoom)
+	}
+	if perZoom[0] == 0 {
+		t.Error("first point should be kept at every zoom")
+	}
+
+	off := &pointThinning{rate: 1, baseZoom: 8}
+	if z := off.minZoom(2); z != 2 {
+		t.Errorf("rate 1 min zoom = %d, want 2", z)
+	}
+}
+
+func TestEncodeLimited(t *testing.T) {
+	tile := maptile.New(0, 0, 0)
+	newLayer := func() *mvt.Layer {
+		fc := geojson.NewFeatureCollection()
+		for i := 0; i < 1000; i++ {
+			f := geojson.NewFeature(orb.Point{float64(i % 40 * 100), float64(i / 40 * 100)})
+			f.Properties["i"] = i
+			fc.Append(f)
+		}
+		return mvt.NewLayer("points", fc)
+	}
+
+	opts := &tileOptions{compression: pmtiles.Gzip, maxTileFeatures: 100}
+	if _, err := encodeLimited(tile, mvt.Layers{newLayer()}, opts); err == nil {
+		t.Error("feature limit not enforced")
+	}
+
+	opts.dropDensest = true
+	data, err := encodeLimited(tile, mvt.Layers{newLayer()}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := pmtiles.Decompress(data, pmtiles.Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := mvt.Unmarshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(layers[0].Features); n != 100 {
+		t.Errorf("kept %d features, want 100", n)
+	}
+
+	opts = &tileOptions{compression: pmtiles.Gzip, maxTileBytes: 1000, dropDensest: true}
+	data, err = encodeLimited(tile, mvt.Layers{newLayer()}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 1000 {
+		t.Errorf("tile is %d bytes, want at most 1000", len(data))
+	}
+}
+
+func TestClusterPoints(t *testing.T) {
+	point := func(x, y, v float64) *geojson.Feature {
+		f := geojson.NewFeature(orb.Point{x, y})
+		f.Properties["v"] = v
+		return f
+	}
+	line := geojson.NewFeature(orb.LineString{{0, 0}, {100, 100}})
+	features := []*geojson.Feature{
+		point(100, 100, 1),
+		line,
+		point(110, 100, 5),
+		point(1000, 1000, 7),
+		point(100, 120, 3),
+	}
+
+	out := clusterPoints(features, 50, []string{"v"})
+	if len(out) != 3 {
+		t.Fatalf("got %d features, want 3", len(out))
+	}
+	c := out[0]
+	if c.Properties["point_count"] != 3 || c.Properties["cluster"] != true {
+		t.Errorf("cluster properties = %v", c.Properties)
+	}
+	if c.Properties["v_sum"] != 9.0 || c.Properties["v_min"] != 1.0 || c.Properties["v_max"] != 5.0 {
+		t.Errorf("cluster aggregates = %v", c.Properties)
+	}
+	if p := c.Geometry.(orb.Point); p != (orb.Point{310.0 / 3, 320.0 / 3}) {
+		t.Errorf("cluster at %v, want members' mean", p)
+	}
+	if out[1] != line || out[2] != features[3] {
+		t.Error("unclustered features should pass through in order")
+	}
+}
+
+func TestAttributeFilter(t *testing.T) {
+	a, err := newAttributeFilter(tiler.TileConfig{
+		Include:          []string{"name", "height", "open", "rank", "detail"},
+		Exclude:          []string{"name"},
+		AttributeTypes:   map[string]string{"height": "float", "open": "bool", "rank": "int"},
+		AttributeMinZoom: map[string]int{"detail": 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := geojson.Properties{
+		"name": "a", "height": "12.5", "open": "no", "rank": 3.7, "detail": "x", "other": 1.0,
+	}
+	a.apply(props)
+	want := geojson.Properties{"height": 12.5, "open": false, "rank": 3.0, "detail": "x"}
+	if fmt.Sprint(props) != fmt.Sprint(want) {
+		t.Errorf("apply = %v, want %v", props, want)
+	}
+
+	a.forTile(props, 9)
+	if _, ok := props["detail"]; ok {
+		t.Error("detail should be stripped below z10")
+	}
+	if props["rank"] != int64(3) {
+		t.Errorf("rank = %#v, want int64 3", props["rank"])
+	}
+
+	props = geojson.Properties{"height": "tall"}
+	a.apply(props)
+	if len(props) != 0 {
+		t.Errorf("uncoercible value kept: %v", props)
+	}
+
+	if _, err := newAttributeFilter(tiler.TileConfig{AttributeTypes: map[string]string{"x": "date"}}); err == nil {
+		t.Error("unknown attribute type accepted")
+	}
+}
+
+func TestTileLayers(t *testing.T) {
+	dir := t.TempDir()
+	area := filepath.Join(dir, "area.geojson")
+	polygon := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"kind":"park"},` +
+		`"geometry":{"type":"Polygon","coordinates":[[[-125,30],[-115,30],[-115,45],[-125,45],[-125,30]]]}}]}`
+	if err := os.WriteFile(area, []byte(polygon), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "layers.pmtiles")
+	layers := []tiler.LayerInput{
+		{Path: "../../../testdata/sample-points.geojson", Config: tiler.TileConfig{Layer: "cities", MinZoom: 2, MaxZoom: 4}},
+		{Path: area, Config: tiler.TileConfig{Layer: "parks", MinZoom: 0, MaxZoom: 3}},
+	}
+	if err := New().TileLayers(context.Background(), layers, path, tiler.TileConfig{Layer: "overlay"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pmtiles.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if h := r.Header(); h.MinZoom != 0 || h.MaxZoom != 4 {
+		t.Errorf("zooms = %d-%d, want 0-4", h.MinZoom, h.MaxZoom)
+	}
+
+	metadata, err := r.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vl, _ := metadata["vector_layers"].([]any)
+	if len(vl) != 2 {
+		t.Fatalf("vector_layers = %v", metadata["vector_layers"])
+	}
+	if l := vl[0].(map[string]any); l["id"] != "cities" || l["minzoom"] != 2.0 {
+		t.Errorf("first layer = %v", l)
+	}
+	if l := vl[1].(map[string]any); l["id"] != "parks" || l["maxzoom"] != 3.0 {
+		t.Errorf("second layer = %v", l)
+	}
+
+	// San Francisco and the park share a tile at z3
+	data, ok, err := r.Tile(3, 1, 3)
+	if err != nil || !ok {
+		t.Fatalf("tile 3/1/3: ok=%v err=%v", ok, err)
+	}
+	raw, err := pmtiles.Decompress(data, pmtiles.Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile, err := mvt.Unmarshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tile) != 2 || tile[0].Name != "cities" || tile[1].Name != "parks" {
+		t.Errorf("tile 3/1/3 layers = %v", tile)
+	}
+
+	if err := New().TileLayers(context.Background(), append(layers, layers[0]), path, tiler.TileConfig{}, nil); err == nil {
+		t.Error("duplicate layer names accepted")
+	}
+}
+
+func TestGeoParquetSource(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "cities.parquet")
+
+	// Two row groups of WKB points with a bbox covering column
+	bboxType := arrow.StructOf(
+		arrow.Field{Name: "xmin", Type: arrow.PrimitiveTypes.Float64},
+		arrow.Field{Name: "ymin", Type: arrow.PrimitiveTypes.Float64},
+		arrow.Field{Name: "xmax", Type: arrow.PrimitiveTypes.Float64},
+		arrow.Field{Name: "ymax", Type: arrow.PrimitiveTypes.Float64},
+	)
+	geo := `{"version":"1.1.0","primary_column":"geometry","columns":{"geometry":{"encoding":"WKB",` +
+		`"geometry_types":["Point"],"covering":{"bbox":{"xmin":["bbox","xmin"],"ymin":["bbox","ymin"],` +
+		`"xmax":["bbox","xmax"],"ymax":["bbox","ymax"]}}}}}`
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "population", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "geometry", Type: arrow.BinaryTypes.Binary, Nullable: true},
+		{Name: "bbox", Type: bboxType},
+	}, nil)
+
+	out, err := os.Create(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := pqarrow.NewFileWriter(schema, out, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AppendKeyValueMetadata("geo", geo); err != nil {
+		t.Fatal(err)
+	}
+	for group := 0; group < 2; group++ {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+		for i := 0; i < 3; i++ {
+			p := orb.Point{-120 + float64(group*3+i), 35}
+			data, err := wkb.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.Field(0).(*array.StringBuilder).Append(fmt.Sprintf("city-%d", group*3+i))
+			b.Field(1).(*array.Int64Builder).Append(int64(1000 * (group*3 + i)))
+			b.Field(2).(*array.BinaryBuilder).Append(data)
+			sb := b.Field(3).(*array.StructBuilder)
+			sb.Append(true)
+			for j, v := range []float64{p[0], p[1], p[0], p[1]} {
+				sb.FieldBuilder(j).(*array.Float64Builder).Append(v)
+			}
+		}
+		rec := b.NewRecord()
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+		rec.Release()
+		b.Release()
+		w.NewBufferedRowGroup()
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := openSource(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	n := 0
+	for {
+		f, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := f.Properties["bbox"]; ok {
+			t.Error("bbox covering column should not be a property")
+		}
+		if f.Properties["population"] != float64(1000*n) || f.Properties["name"] != fmt.Sprintf("city-%d", n) {
+			t.Errorf("row %d properties = %v", n, f.Properties)
+		}
+		if p, ok := f.Geometry.(orb.Point); !ok || p[0] != -120+float64(n) {
+			t.Errorf("row %d geometry = %v", n, f.Geometry)
+		}
+		n++
+	}
+	if n != 6 {
+		t.Errorf("read %d rows, want 6", n)
+	}
+
+	if err := New().Tile(context.Background(), input, filepath.Join(dir, "cities.pmtiles"), tiler.TileConfig{MaxZoom: 5, Layer: "cities"}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFlatGeobuf writes points as a FlatGeobuf file with name (string)
+// and population (long) columns and a packed R-tree with the given node
+// size, or no index when nodeSize is 0.
+func writeFlatGeobuf(path string, points []orb.Point, nodeSize uint16) error {
+	b := flatbuffers.NewBuilder(0)
+	var columns []flatbuffers.UOffsetT
+	for _, col := range []struct {
+		name string
+		typ  byte
+	}{{"name", fgbString}, {"population", fgbLong}} {
+		name := b.CreateString(col.name)
+		b.StartObject(2)
+		b.PrependUOffsetTSlot(0, name, 0)
+		b.PrependByteSlot(1, col.typ, fgbByte)
+		columns = append(columns, b.EndObject())
+	}
+	b.StartVector(4, len(columns), 4)
+	for i := len(columns) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(columns[i])
+	}
+	vec := b.EndVector(len(columns))
+	b.StartObject(10)
+	b.PrependByteSlot(2, fgbPoint, fgbUnknown)
+	b.PrependUOffsetTSlot(7, vec, 0)
+	b.PrependUint64Slot(8, uint64(len(points)), 0)
+	b.PrependUint16Slot(9, nodeSize, 16)
+	b.Finish(b.EndObject())
+
+	out := append([]byte{}, fgbMagic...)
+	out = append(out, 0)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(b.FinishedBytes())))
+	out = append(out, b.FinishedBytes()...)
+
+	var features []byte
+	var offsets []uint64
+	for i, p := range points {
+		props := binary.LittleEndian.AppendUint16(nil, 0)
+		name := fmt.Sprintf("city-%d", i)
+		props = binary.LittleEndian.AppendUint32(props, uint32(len(name)))
+		props = append(props, name...)
+		props = binary.LittleEndian.AppendUint16(props, 1)
+		props = binary.LittleEndian.AppendUint64(props, uint64(1000*i))
+
+		b.Reset()
+		b.StartVector(8, 2, 8)
+		b.PrependFloat64(p[1])
+		b.PrependFloat64(p[0])
+		xy := b.EndVector(2)
+		b.StartObject(8)
+		b.PrependUOffsetTSlot(1, xy, 0)
+		geom := b.EndObject()
+		propsVec := b.CreateByteVector(props)
+		b.StartObject(3)
+		b.PrependUOffsetTSlot(0, geom, 0)
+		b.PrependUOffsetTSlot(1, propsVec, 0)
+		b.Finish(b.EndObject())
+		offsets = append(offsets, uint64(len(features)))
+		features = binary.LittleEndian.AppendUint32(features, uint32(len(b.FinishedBytes())))
+		features = append(features, b.FinishedBytes()...)
+	}
+
+	if nodeSize > 0 {
+		leaves := make([]orb.Bound, len(points))
+		for i, p := range points {
+			leaves[i] = p.Bound()
+		}
+		out = append(out, packedRTree(leaves, offsets, int(nodeSize))...)
+	}
+	out = append(out, features...)
+	return os.WriteFile(path, out, 0644)
+}
+
+// packedRTree lays out an R-tree over leaves as the reference FlatGeobuf
+// writer does: levels from the root down, leaves last, each leaf holding
+// its feature's offset and each internal node the index of its first
+// child. Like the reference, there is always a root above the leaves.
+func packedRTree(leaves []orb.Bound, offsets []uint64, nodeSize int) []byte {
+	levels := [][]orb.Bound{leaves} // leaves first
+	for len(levels) == 1 || len(levels[len(levels)-1]) > 1 {
+		below := levels[len(levels)-1]
+		var level []orb.Bound
+		for i := 0; i < len(below); i += nodeSize {
+			bound := below[i]
+			for _, child := range below[i+1 : min(i+nodeSize, len(below))] {
+				bound = bound.Union(child)
+			}
+			level = append(level, bound)
+		}
+		levels = append(levels, level)
+	}
+
+	first := make([]int, len(levels)) // node index of each level's first node
+	n := 0
+	for i := len(levels) - 1; i >= 0; i-- {
+		first[i] = n
+		n += len(levels[i])
+	}
+
+	var out []byte
+	for i := len(levels) - 1; i >= 0; i-- {
+		for j, bound := range levels[i] {
+			for _, v := range []float64{bound.Min[0], bound.Min[1], bound.Max[0], bound.Max[1]} {
+				out = binary.LittleEndian.AppendUint64(out, math.Float64bits(v))
+			}
+			if i == 0 {
+				out = binary.LittleEndian.AppendUint64(out, offsets[j])
+			} else {
+				out = binary.LittleEndian.AppendUint64(out, uint64(first[i-1]+j*nodeSize))
+			}
+		}
+	}
+	return out
+}
+
+func TestFlatGeobufIndexSize(t *testing.T) {
+	// Sizes from the reference calcTreeSize: 40 bytes a node
+	tests := []struct {
+		count    uint64
+		nodeSize uint16
+		want     uint64
+	}{
+		{0, 16, 0},
+		{5, 0, 0},
+		{1, 16, 80},   // a leaf and the root
+		{2, 16, 120},  // two leaves and the root
+		{16, 16, 680}, // 16 leaves and the root
+		{17, 16, 800}, // 17 leaves, 2 nodes and the root
+		{3, 2, 240},   // 3 leaves, 2 nodes and the root
+		{256, 16, 10920},
+	}
+	for _, tt := range tests {
+		if got := fgbIndexSize(tt.count, tt.nodeSize); got != tt.want {
+			t.Errorf("fgbIndexSize(%d, %d) = %d, want %d", tt.count, tt.nodeSize, got, tt.want)
+		}
+	}
+}
+
+func TestFlatGeobufSource(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "cities.fgb")
+	points := []orb.Point{{-122.4, 37.8}, {-118.2, 34.1}, {-74, 40.7}}
+	if err := writeFlatGeobuf(input, points, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := openSource(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ok := src.(inPlaceReader)
+	if !ok {
+		t.Fatalf("flatgeobuf source %T is not read in place", src)
+	}
+	defer r.Close()
+	for i := 0; ; i++ {
+		f, err := r.Next()
+		if err == io.EOF {
+			if i != len(points) {
+				t.Errorf("read %d features, want %d", i, len(points))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Geometry != points[i] || f.Properties["name"] != fmt.Sprintf("city-%d", i) || f.Properties["population"] != float64(1000*i) {
+			t.Errorf("feature %d = %v %v", i, f.Geometry, f.Properties)
+		}
+		again, err := r.readAt(r.location())
+		if err != nil || again.Geometry != f.Geometry || again.Properties["name"] != f.Properties["name"] {
+			t.Errorf("readAt feature %d = %v, %v", i, again, err)
+		}
+	}
+
+	// Features are read from the file in pass 2, so the attribute filter
+	// must apply there too
+	path := filepath.Join(dir, "cities.pmtiles")
+	config := tiler.TileConfig{Layer: "cities", MaxZoom: 3, ReduceRate: 1, Include: []string{"name"}}
+	if err := New().Tile(context.Background(), input, path, config, nil); err != nil {
+		t.Fatal(err)
+	}
+	pm, err := pmtiles.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pm.Close()
+	data, ok, err := pm.Tile(0, 0, 0)
+	if err != nil || !ok {
+		t.Fatalf("tile 0/0/0: ok=%v err=%v", ok, err)
+	}
+	raw, err := pmtiles.Decompress(data, pmtiles.Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile, err := mvt.Unmarshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tile) != 1 || len(tile[0].Features) != len(points) {
+		t.Fatalf("tile 0/0/0 = %v", tile)
+	}
+	for _, f := range tile[0].Features {
+		if _, ok := f.Properties["population"]; ok || f.Properties["name"] == nil {
+			t.Errorf("feature properties = %v", f.Properties)
+		}
+	}
+}
+
+func TestFlatGeobufSingleFeature(t *testing.T) {
+	// The index of a one-feature file still has a root node
+	dir := t.TempDir()
+	input := filepath.Join(dir, "one.fgb")
+	if err := writeFlatGeobuf(input, []orb.Point{{-0.1, 51.5}}, 16); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "one.pmtiles")
+	if err := New().Tile(context.Background(), input, path, tiler.TileConfig{Layer: "one", MaxZoom: 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// gridPoints returns n points spread over the contiguous US, off tile
+// boundaries.
+func gridPoints(n int) []orb.Point {
+	points := make([]orb.Point, n)
+	for i := range points {
+		points[i] = orb.Point{-120 + float64(i%20)*2.113, 30 + float64(i/20)*1.271}
+	}
+	return points
+}
+
+func TestFlatGeobufIndexSearch(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "grid.fgb")
+	points := gridPoints(200)
+	if err := writeFlatGeobuf(input, points, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := openSource(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	r, ok := src.(indexedReader)
+	if !ok || !r.indexed() {
+		t.Fatalf("flatgeobuf source %T is not indexed", src)
+	}
+
+	bound := orb.Bound{Min: orb.Point{-110, 33}, Max: orb.Point{-95, 40}}
+	var want []uint64
+	for i, p := range points {
+		if bound.Contains(p) {
+			want = append(want, uint64(i))
+		}
+	}
+	var got []uint64
+	err = r.search(bound, func(i, offset uint64) error {
+		f, err := r.readFeatureAt(offset)
+		if err != nil {
+			return err
+		}
+		if f.Geometry != points[i] {
+			t.Errorf("feature %d at offset %d = %v, want %v", i, offset, f.Geometry, points[i])
+		}
+		got = append(got, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("search found %v, want %v", got, want)
+	}
+}
+
+func TestFlatGeobufIndexedTiles(t *testing.T) {
+	// Searching the index in pass 2 must produce the same archive as
+	// reading each feature in place
+	dir := t.TempDir()
+	points := gridPoints(400)
+	var archives [][]byte
+	for _, nodeSize := range []uint16{0, 16} {
+		input := filepath.Join(dir, fmt.Sprintf("grid-%d.fgb", nodeSize))
+		if err := writeFlatGeobuf(input, points, nodeSize); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("grid-%d.pmtiles", nodeSize))
+		config := tiler.TileConfig{Layer: "grid", MaxZoom: 6, Include: []string{"name"}}
+		if err := New().Tile(context.Background(), input, path, config, nil); err != nil {
+			t.Fatalf("node size %d: %v", nodeSize, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archives = append(archives, data)
+	}
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("indexed and unindexed sources produced different archives")
+	}
+}
+
+func TestTileProgressAndCancel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "points.pmtiles")
+	config := tiler.TileConfig{MinZoom: 0, MaxZoom: 6}
+
+	var updates []tiler.Progress
+	err := New().Tile(context.Background(), "../../../testdata/sample-points.geojson", path, config, func(p tiler.Progress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("got %d progress updates, want one per zoom and a final one", len(updates))
+	}
+	for i := 1; i < len(updates); i++ {
+		prev, p := updates[i-1], updates[i]
+		if p.Zoom < prev.Zoom || p.TilesDone < prev.TilesDone || p.Percent < prev.Percent {
+			t.Errorf("progress went backwards: %+v then %+v", prev, p)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := updates[len(updates)-1]
+	if last.Percent != 100 || last.Zoom != 6 || last.TilesDone == 0 || last.BytesWritten <= 0 || last.BytesWritten > info.Size() {
+		t.Errorf("final progress = %+v", last)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = New().Tile(ctx, "../../../testdata/sample-points.geojson", filepath.Join(dir, "cancelled.pmtiles"), config, nil)
+	if err != context.Canceled {
+		t.Errorf("cancelled run returned %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cancelled.pmtiles")); !os.IsNotExist(err) {
+		t.Error("cancelled run wrote an archive")
+	}
+}
+
+func TestTileKeepsPreviousArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "points.pmtiles")
+	config := tiler.TileConfig{MinZoom: 0, MaxZoom: 4}
+	if err := New().Tile(context.Background(), "../../../testdata/sample-points.geojson", path, config, nil); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed run must leave the previous archive untouched
+	err = New().Tile(context.Background(), filepath.Join(dir, "missing.geojson"), path, config, nil)
+	if err == nil {
+		t.Fatal("tiling a missing source succeeded")
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("failed run modified the previous archive")
+	}
+
+	// No temp files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "points.pmtiles" {
+			t.Errorf("unexpected file left behind: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package gotiler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+)
+
+// Tile limits matching tippecanoe's defaults.
+const (
+	defaultMaxTileBytes    = 500000
+	defaultMaxTileFeatures = 200000
+	defaultReduceRate      = 2.5
+)
+
+// pointThinning assigns each point a minimum zoom so that one in
+// rate^(base-z) points appears at each zoom z below the base zoom, like
+// tippecanoe's -r. Each zoom earns credit for 1/rate^(base-z) of a point
+// per point seen; a point starts at the shallowest zoom holding a whole
+// point of credit and spends it there and at every deeper zoom.
+type pointThinning struct {
+	rate     float64
+	baseZoom int
+	credit   []float64
+}
+
+// minZoom returns the lowest zoom at or above minZoom at which the next
+// point appears.
+func (p *pointThinning) minZoom(minZoom int) int {
+	if p.rate <= 1 || minZoom >= p.baseZoom {
+		return minZoom
+	}
+	if p.credit == nil {
+		p.credit = make([]float64, p.baseZoom)
+		// Start with a point of credit so the first point shows everywhere
+		for z := range p.credit {
+			p.credit[z] = 1 - p.share(z)
+		}
+	}
+
+	z := p.baseZoom
+	for i := p.baseZoom - 1; i >= minZoom; i-- {
+		p.credit[i] += p.share(i)
+		if p.credit[i] >= 1 {
+			z = i
+		}
+	}
+	for i := z; i < p.baseZoom; i++ {
+		p.credit[i]--
+	}
+	return z
+}
+
+// share is the fraction of points that appear at zoom z.
+func (p *pointThinning) share(z int) float64 {
+	return math.Pow(p.rate, -float64(p.baseZoom-z))
+}
+
+// isPoint reports whether a geometry is subject to point thinning.
+func isPoint(g orb.Geometry) bool {
+	switch g.(type) {
+	case orb.Point, orb.MultiPoint:
+		return true
+	}
+	return false
+}
+
+// encodeLimited encodes a tile's projected layers, enforcing the per-tile
+// feature and byte limits across all layers. With dropDensest the features
+// closest to their spatial neighbours are dropped from every layer in
+// proportion until the tile fits; otherwise exceeding a limit is an error,
+// as in tippecanoe.
+func encodeLimited(tile maptile.Tile, layers mvt.Layers, opts *tileOptions) ([]byte, error) {
+	if n := countFeatures(layers); opts.maxTileFeatures > 0 && n > opts.maxTileFeatures {
+		if !opts.dropDensest {
+			return nil, fmt.Errorf("tile %d/%d/%d has %d features, more than the limit of %d (enable DropDensest or NoFeatureLimit)",
+				tile.Z, tile.X, tile.Y, n, opts.maxTileFeatures)
+		}
+		thinLayers(layers, opts.maxTileFeatures)
+	}
+
+	for {
+		data, err := mvt.Marshal(layers)
+		if err != nil {
+			return nil, fmt.Errorf("encoding tile %d/%d/%d: %w", tile.Z, tile.X, tile.Y, err)
+		}
+		data, err = pmtiles.Compress(data, opts.compression)
+		if err != nil {
+			return nil, err
+		}
+		if opts.maxTileBytes <= 0 || len(data) <= opts.maxTileBytes {
+			return data, nil
+		}
+
+		if !opts.dropDensest {
+			return nil, fmt.Errorf("tile %d/%d/%d is %d bytes, more than the limit of %d (enable DropDensest or NoTileSizeLimit)",
+				tile.Z, tile.X, tile.Y, len(data), opts.maxTileBytes)
+		}
+		// Aim a little under the limit, as tippecanoe does
+		n := countFeatures(layers)
+		target := int(float64(n) * float64(opts.maxTileBytes) / float64(len(data)) * 0.9)
+		if target >= n {
+			target = n - 1
+		}
+		if target <= 0 {
+			return nil, fmt.Errorf("tile %d/%d/%d: a single feature exceeds the %d byte limit", tile.Z, tile.X, tile.Y, opts.maxTileBytes)
+		}
+		thinLayers(layers, target)
+	}
+}
+
+func countFeatures(layers mvt.Layers) int {
+	n := 0
+	for _, l := range layers {
+		n += len(l.Features)
+	}
+	return n
+}
+
+// thinLayers drops the densest features so that about target remain,
+// taking the same share from each layer and at least one feature overall.
+func thinLayers(layers mvt.Layers, target int) {
+	n := countFeatures(layers)
+	ratio := float64(target) / float64(n)
+	kept := 0
+	var largest *mvt.Layer
+	for _, l := range layers {
+		l.Features = dropDensest(l.Features, int(float64(len(l.Features))*ratio))
+		kept += len(l.Features)
+		if largest == nil || len(l.Features) > len(largest.Features) {
+			largest = l
+		}
+	}
+	if kept >= n {
+		largest.Features = dropDensest(largest.Features, len(largest.Features)-1)
+	}
+}
+
+// dropDensest keeps the n features with the largest gaps to their
+// predecessor in spatial (Z-order) sequence, preserving feature order.
+func dropDensest(features []*geojson.Feature, n int) []*geojson.Feature {
+	if n >= len(features) {
+		return features
+	}
+
+	type ranked struct {
+		index int
+		key   uint64
+		at    orb.Point
+		gap   float64
+	}
+	rs := make([]ranked, len(features))
+	for i, f := range features {
+		at := f.Geometry.Bound().Center()
+		rs[i] = ranked{index: i, key: zOrder(at), at: at}
+	}
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].key < rs[j].key })
+	for i := range rs {
+		if i == 0 {
+			rs[i].gap = math.Inf(1)
+			continue
+		}
+		dx := rs[i].at[0] - rs[i-1].at[0]
+		dy := rs[i].at[1] - rs[i-1].at[1]
+		rs[i].gap = dx*dx + dy*dy
+	}
+
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].gap > rs[j].gap })
+	keep := rs[:n]
+	sort.Slice(keep, func(i, j int) bool { return keep[i].index < keep[j].index })
+
+	out := make([]*geojson.Feature, n)
+	for i, r := range keep {
+		out[i] = features[r.index]
+	}
+	return out
+}
+
+// zOrder interleaves the bits of tile-space coordinates into a Morton key.
+func zOrder(p orb.Point) uint64 {
+	x := uint32(math.Max(0, math.Min(p[0]+mvt.DefaultExtent, 3*mvt.DefaultExtent)))
+	y := uint32(math.Max(0, math.Min(p[1]+mvt.DefaultExtent, 3*mvt.DefaultExtent)))
+	var key uint64
+	for i := 0; i < 32; i++ {
+		key |= uint64(x>>i&1)<<(2*i) | uint64(y>>i&1)<<(2*i+1)
+	}
+	return key
+}
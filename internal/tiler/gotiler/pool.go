@@ -0,0 +1,96 @@
+package gotiler
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// errEncodeAborted stops the producer after a tile failed to encode.
+var errEncodeAborted = errors.New("tile encoding aborted")
+
+// tileJob is one tile handed to the worker pool.
+type tileJob struct {
+	tileID uint64
+	refs   []featureRef
+	data   []byte
+	err    error
+	done   chan struct{}
+}
+
+// encodeTiles encodes every tile yielded by the sorter on a pool of
+// workers and passes the results to add in tile ID order, so the archive
+// is byte-identical for any worker count. At most a few tiles per worker
+// are in flight at once.
+func encodeTiles(sorter *tileSorter, workers int, encode func(tileID uint64, refs []featureRef) ([]byte, error), add func(tileID uint64, data []byte) error) error {
+	if workers <= 1 {
+		return sorter.each(func(tileID uint64, refs []featureRef) error {
+			data, err := encode(tileID, refs)
+			if err != nil || len(data) == 0 {
+				return err
+			}
+			return add(tileID, data)
+		})
+	}
+
+	jobs := make(chan *tileJob)
+	ordered := make(chan *tileJob, workers*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.data, j.err = encode(j.tileID, j.refs)
+				close(j.done)
+			}
+		}()
+	}
+
+	// Collect results in submission order; after a failure keep draining
+	// so workers and the producer can finish.
+	var failed atomic.Bool
+	collected := make(chan error, 1)
+	go func() {
+		var err error
+		for j := range ordered {
+			<-j.done
+			if err != nil {
+				continue
+			}
+			if j.err != nil {
+				err = j.err
+			} else if len(j.data) > 0 {
+				err = add(j.tileID, j.data)
+			}
+			if err != nil {
+				failed.Store(true)
+			}
+		}
+		collected <- err
+	}()
+
+	produceErr := sorter.each(func(tileID uint64, refs []featureRef) error {
+		if failed.Load() {
+			return errEncodeAborted
+		}
+		// The sorter reuses refs between calls
+		j := &tileJob{
+			tileID: tileID,
+			refs:   append([]featureRef(nil), refs...),
+			done:   make(chan struct{}),
+		}
+		ordered <- j
+		jobs <- j
+		return nil
+	})
+	close(jobs)
+	close(ordered)
+	wg.Wait()
+
+	if err := <-collected; err != nil {
+		return err
+	}
+	return produceErr
+}
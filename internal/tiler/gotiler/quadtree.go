@@ -0,0 +1,208 @@
+package gotiler
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/paulmach/orb/planar"
+)
+
+// tileRelation describes how a geometry relates to a tile's bounds.
+type tileRelation int
+
+const (
+	disjoint   tileRelation = iota // no overlap
+	intersects                     // partial overlap
+	covers                         // geometry contains the whole tile
+)
+
+// assignTiles calls fn for every tile in [minZoom, maxZoom] that geom
+// touches. It descends the quadtree from the covering tiles at minZoom and
+// only tests children of tiles the geometry intersects; once a polygon
+// covers a tile, all of its descendants are emitted without further tests.
+func assignTiles(geom orb.Geometry, minZoom, maxZoom int, fn func(maptile.Tile) error) error {
+	var visit func(t maptile.Tile) error
+	visit = func(t maptile.Tile) error {
+		switch relateTile(geom, t.Bound()) {
+		case disjoint:
+			return nil
+		case covers:
+			return eachDescendant(t, maxZoom, fn)
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+		if int(t.Z) >= maxZoom {
+			return nil
+		}
+		for _, c := range t.Children() {
+			if err := visit(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, t := range tilesInBounds(geom.Bound(), uint32(minZoom)) {
+		if err := visit(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachDescendant calls fn for t and every descendant of t down to maxZoom.
+func eachDescendant(t maptile.Tile, maxZoom int, fn func(maptile.Tile) error) error {
+	for z := int(t.Z); z <= maxZoom; z++ {
+		dz := uint(z - int(t.Z))
+		minX, minY := t.X<<dz, t.Y<<dz
+		n := uint32(1) << dz
+		for x := minX; x < minX+n; x++ {
+			for y := minY; y < minY+n; y++ {
+				if err := fn(maptile.New(x, y, maptile.Zoom(z))); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// relateTile classifies a geometry against tile bounds using exact
+// segment/rectangle tests rather than bounding boxes.
+func relateTile(geom orb.Geometry, b orb.Bound) tileRelation {
+	if !geom.Bound().Intersects(b) {
+		return disjoint
+	}
+
+	switch g := geom.(type) {
+	case orb.Point:
+		if b.Contains(g) {
+			return intersects
+		}
+		return disjoint
+
+	case orb.MultiPoint:
+		for _, p := range g {
+			if b.Contains(p) {
+				return intersects
+			}
+		}
+		return disjoint
+
+	case orb.LineString:
+		if pathIntersects(g, b) {
+			return intersects
+		}
+		return disjoint
+
+	case orb.MultiLineString:
+		for _, ls := range g {
+			if pathIntersects(ls, b) {
+				return intersects
+			}
+		}
+		return disjoint
+
+	case orb.Ring:
+		return relatePolygon(orb.Polygon{g}, b)
+
+	case orb.Polygon:
+		return relatePolygon(g, b)
+
+	case orb.MultiPolygon:
+		rel := disjoint
+		for _, poly := range g {
+			if r := relatePolygon(poly, b); r > rel {
+				rel = r
+			}
+		}
+		return rel
+
+	case orb.Collection:
+		rel := disjoint
+		for _, child := range g {
+			if r := relateTile(child, b); r > rel {
+				rel = r
+			}
+		}
+		return rel
+
+	default:
+		// For unknown types, trust bounding box check
+		return intersects
+	}
+}
+
+// relatePolygon tests a polygon against tile bounds. If no ring edge
+// touches the tile, the tile is either wholly inside the polygon (and not
+// in a hole) or wholly outside it, which the tile center decides.
+func relatePolygon(poly orb.Polygon, b orb.Bound) tileRelation {
+	if len(poly) == 0 {
+		return disjoint
+	}
+	for _, ring := range poly {
+		if pathIntersects(orb.LineString(ring), b) {
+			return intersects
+		}
+	}
+	if planar.PolygonContains(poly, b.Center()) {
+		return covers
+	}
+	return disjoint
+}
+
+// pathIntersects reports whether any vertex or segment of ls touches b.
+func pathIntersects(ls orb.LineString, b orb.Bound) bool {
+	if len(ls) == 1 {
+		return b.Contains(ls[0])
+	}
+	for i := 1; i < len(ls); i++ {
+		if segmentIntersects(ls[i-1], ls[i], b) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentIntersects is a Liang–Barsky clip test of segment a-b against r.
+func segmentIntersects(a, b orb.Point, r orb.Bound) bool {
+	if r.Contains(a) || r.Contains(b) {
+		return true
+	}
+	if math.Max(a[0], b[0]) < r.Min[0] || math.Min(a[0], b[0]) > r.Max[0] ||
+		math.Max(a[1], b[1]) < r.Min[1] || math.Min(a[1], b[1]) > r.Max[1] {
+		return false
+	}
+
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t0, t1 := 0.0, 1.0
+	for _, e := range [4][2]float64{
+		{-dx, a[0] - r.Min[0]},
+		{dx, r.Max[0] - a[0]},
+		{-dy, a[1] - r.Min[1]},
+		{dy, r.Max[1] - a[1]},
+	} {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			if t > t1 {
+				return false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return false
+			}
+			t1 = math.Min(t1, t)
+		}
+	}
+	return t0 <= t1
+}
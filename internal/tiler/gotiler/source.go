@@ -0,0 +1,219 @@
+package gotiler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// featureReader streams features from a source file one at a time.
+// Next returns io.EOF after the last feature.
+type featureReader interface {
+	Next() (*geojson.Feature, error)
+	Close() error
+}
+
+// inPlaceReader is a featureReader whose features can be read back from
+// the source by location, so the tiler references them in place instead
+// of spooling copies. readAt must be safe for concurrent use.
+type inPlaceReader interface {
+	featureReader
+	location() featureRef // of the feature last returned by Next
+	extent() uint64       // locations are below this offset
+	readAt(ref featureRef) (*geojson.Feature, error)
+}
+
+// indexedReader is an inPlaceReader with a spatial index. Pass 1 then
+// only marks the tiles a layer touches, and pass 2 searches the index for
+// each tile's features. Positions passed to search count features in the
+// order Next returns them. search and readFeatureAt must be safe for
+// concurrent use.
+type indexedReader interface {
+	inPlaceReader
+	indexed() bool
+	search(b orb.Bound, fn func(i, offset uint64) error) error
+	readFeatureAt(offset uint64) (*geojson.Feature, error)
+}
+
+// recordSeparator prefixes each record in RFC 8142 GeoJSON text sequences.
+const recordSeparator = 0x1e
+
+// parquetMagic starts every Parquet file.
+const parquetMagic = "PAR1"
+
+// openSource opens inputPath with a reader chosen by file extension, or
+// by sniffing the content for FlatGeobuf, Parquet and GeoJSON text
+// sequences.
+func openSource(inputPath string) (featureReader, error) {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening source: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(inputPath)) {
+	case ".fgb":
+		return openFlatGeobufSource(f)
+	case ".parquet", ".geoparquet":
+		return openParquetSource(f)
+	case ".geojsonl", ".geojsonseq", ".geojsons", ".ndjson":
+		return &geojsonSeqReader{f: f, r: bufio.NewReaderSize(f, 1<<20)}, nil
+	}
+
+	br := bufio.NewReaderSize(f, 1<<20)
+	if b, err := br.Peek(4); err == nil && bytes.Equal(b, fgbMagic[:4]) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("opening source: %w", err)
+		}
+		return openFlatGeobufSource(f)
+	}
+	if b, err := br.Peek(4); err == nil && string(b) == parquetMagic {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("opening source: %w", err)
+		}
+		return openParquetSource(f)
+	}
+
+	// Some tools write text sequences with a .geojson extension
+	if b, err := br.Peek(1); err == nil && b[0] == recordSeparator {
+		return &geojsonSeqReader{f: f, r: br}, nil
+	}
+	return &geojsonReader{f: f, dec: json.NewDecoder(br)}, nil
+}
+
+func openFlatGeobufSource(f *os.File) (featureReader, error) {
+	r, err := openFlatGeobuf(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func openParquetSource(f *os.File) (featureReader, error) {
+	r, err := openGeoParquet(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// geojsonReader decodes the features array of a FeatureCollection
+// incrementally, so the whole document is never held in memory.
+type geojsonReader struct {
+	f       *os.File
+	dec     *json.Decoder
+	started bool
+	done    bool
+}
+
+// Next returns the next feature of the collection.
+func (r *geojsonReader) Next() (*geojson.Feature, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	if !r.started {
+		if err := r.seekFeatures(); err != nil {
+			return nil, err
+		}
+		r.started = true
+	}
+	if !r.dec.More() {
+		r.done = true
+		return nil, io.EOF
+	}
+	f := &geojson.Feature{}
+	if err := r.dec.Decode(f); err != nil {
+		return nil, fmt.Errorf("parsing geojson feature: %w", err)
+	}
+	return f, nil
+}
+
+// seekFeatures advances the decoder to the first element of "features".
+func (r *geojsonReader) seekFeatures() error {
+	if err := expectDelim(r.dec, '{'); err != nil {
+		return fmt.Errorf("parsing geojson: %w", err)
+	}
+	for r.dec.More() {
+		tok, err := r.dec.Token()
+		if err != nil {
+			return fmt.Errorf("parsing geojson: %w", err)
+		}
+		if tok == "features" {
+			if err := expectDelim(r.dec, '['); err != nil {
+				return fmt.Errorf("parsing geojson features: %w", err)
+			}
+			return nil
+		}
+		// Skip the value of any other member ("type", "bbox", "crs", ...)
+		var skip json.RawMessage
+		if err := r.dec.Decode(&skip); err != nil {
+			return fmt.Errorf("parsing geojson: %w", err)
+		}
+	}
+	return errors.New("parsing geojson: no features array in FeatureCollection")
+}
+
+// Close closes the underlying file.
+func (r *geojsonReader) Close() error {
+	return r.f.Close()
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// geojsonSeqReader reads newline-delimited GeoJSON features, with or
+// without RFC 8142 record separators.
+type geojsonSeqReader struct {
+	f    *os.File
+	r    *bufio.Reader
+	line int
+}
+
+// Next returns the feature on the next non-empty line.
+func (r *geojsonSeqReader) Next() (*geojson.Feature, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("reading geojson sequence: %w", err)
+		}
+		r.line++
+
+		line = bytes.TrimSpace(bytes.TrimLeft(line, "\x1e"))
+		if len(line) == 0 {
+			continue
+		}
+		f, perr := geojson.UnmarshalFeature(line)
+		if perr != nil {
+			return nil, fmt.Errorf("parsing geojson sequence line %d: %w", r.line, perr)
+		}
+		return f, nil
+	}
+}
+
+// Close closes the underlying file.
+func (r *geojsonSeqReader) Close() error {
+	return r.f.Close()
+}
@@ -0,0 +1,354 @@
+package gotiler
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/paulmach/orb/encoding/wkb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// defaultMemoryBudget applies when TileConfig.MemoryBudget is zero.
+const defaultMemoryBudget = 256 << 20
+
+// tileRefSize is the on-disk size of a tileRef in sorted run files.
+const tileRefSize = 8 + 8 + 4
+
+// featureStore spools decoded features to a temporary file so that tiles
+// can be built without holding the source in memory. Each feature is
+// stored as WKB geometry followed by JSON properties.
+type featureStore struct {
+	f      *os.File
+	w      *bufio.Writer
+	offset uint64
+}
+
+// featureRef locates a stored feature.
+type featureRef struct {
+	offset uint64
+	length uint32
+}
+
+func newFeatureStore(dir string) (*featureStore, error) {
+	f, err := os.CreateTemp(dir, "features-*")
+	if err != nil {
+		return nil, err
+	}
+	return &featureStore{f: f, w: bufio.NewWriterSize(f, 1<<20)}, nil
+}
+
+// append stores a feature and returns its location.
+func (s *featureStore) append(f *geojson.Feature) (featureRef, error) {
+	geom, err := wkb.Marshal(f.Geometry)
+	if err != nil {
+		return featureRef{}, fmt.Errorf("encoding geometry: %w", err)
+	}
+	props, err := json.Marshal(f.Properties)
+	if err != nil {
+		return featureRef{}, fmt.Errorf("encoding properties: %w", err)
+	}
+
+	var tmp [binary.MaxVarintLen64]byte
+	start := s.offset
+	for _, part := range [][]byte{geom, props} {
+		n := binary.PutUvarint(tmp[:], uint64(len(part)))
+		if _, err := s.w.Write(tmp[:n]); err != nil {
+			return featureRef{}, err
+		}
+		if _, err := s.w.Write(part); err != nil {
+			return featureRef{}, err
+		}
+		s.offset += uint64(n + len(part))
+	}
+	return featureRef{offset: start, length: uint32(s.offset - start)}, nil
+}
+
+// reserve skips n bytes of the spool, leaving a sparse hole, and returns
+// where the range starts. Layers read in place take their feature
+// locations from such a range so they never collide with spooled ones.
+func (s *featureStore) reserve(n uint64) (uint64, error) {
+	if err := s.w.Flush(); err != nil {
+		return 0, err
+	}
+	if _, err := s.f.Seek(int64(n), io.SeekCurrent); err != nil {
+		return 0, err
+	}
+	start := s.offset
+	s.offset += n
+	return start, nil
+}
+
+// finish flushes buffered writes so features can be read back.
+func (s *featureStore) finish() error {
+	return s.w.Flush()
+}
+
+// read decodes the feature at ref. Every call returns a fresh copy, so
+// callers may mutate the geometry freely.
+func (s *featureStore) read(ref featureRef) (*geojson.Feature, error) {
+	buf := make([]byte, ref.length)
+	if _, err := s.f.ReadAt(buf, int64(ref.offset)); err != nil {
+		return nil, fmt.Errorf("reading stored feature: %w", err)
+	}
+
+	var parts [2][]byte
+	rest := buf
+	for i := range parts {
+		n, k := binary.Uvarint(rest)
+		if k <= 0 || uint64(len(rest)-k) < n {
+			return nil, errors.New("corrupt stored feature")
+		}
+		parts[i] = rest[k : k+int(n)]
+		rest = rest[k+int(n):]
+	}
+
+	geom, err := wkb.Unmarshal(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decoding stored geometry: %w", err)
+	}
+	f := geojson.NewFeature(geom)
+	if err := json.Unmarshal(parts[1], &f.Properties); err != nil {
+		return nil, fmt.Errorf("decoding stored properties: %w", err)
+	}
+	if f.Properties == nil {
+		f.Properties = geojson.Properties{}
+	}
+	return f, nil
+}
+
+// Close closes the spool file. The file itself is removed with the
+// tiler's temp directory.
+func (s *featureStore) Close() error {
+	return s.f.Close()
+}
+
+// tileRef assigns a stored feature to a tile.
+type tileRef struct {
+	tileID  uint64
+	feature featureRef
+}
+
+func (a tileRef) less(b tileRef) bool {
+	if a.tileID != b.tileID {
+		return a.tileID < b.tileID
+	}
+	return a.feature.offset < b.feature.offset
+}
+
+// tileSorter is an external sort of tile assignments by tile ID. Refs are
+// buffered up to a limit, then sorted and spilled as run files that are
+// merged on iteration. Ties keep source order so output is deterministic.
+type tileSorter struct {
+	dir   string
+	limit int
+	buf   []tileRef
+	runs  []string
+	count int64 // refs added
+}
+
+func newTileSorter(dir string, memoryBudget int64) *tileSorter {
+	limit := int(memoryBudget / 2 / tileRefSize)
+	if limit < 1024 {
+		limit = 1024
+	}
+	return &tileSorter{dir: dir, limit: limit}
+}
+
+// add buffers a tile assignment, spilling to disk when the buffer is full.
+func (s *tileSorter) add(ref tileRef) error {
+	s.count++
+	s.buf = append(s.buf, ref)
+	if len(s.buf) >= s.limit {
+		return s.spill()
+	}
+	return nil
+}
+
+func (s *tileSorter) sortBuf() {
+	sort.Slice(s.buf, func(i, j int) bool { return s.buf[i].less(s.buf[j]) })
+}
+
+// spill writes the sorted buffer to a new run file.
+func (s *tileSorter) spill() error {
+	s.sortBuf()
+
+	f, err := os.CreateTemp(s.dir, "run-*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+	var rec [tileRefSize]byte
+	for _, r := range s.buf {
+		encodeTileRef(rec[:], r)
+		if _, err := w.Write(rec[:]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	s.runs = append(s.runs, f.Name())
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// each calls fn once per tile, in ascending tile ID order, with the
+// features assigned to it in source order.
+func (s *tileSorter) each(fn func(tileID uint64, features []featureRef) error) error {
+	if len(s.runs) == 0 {
+		s.sortBuf()
+		return groupTileRefs(sliceIter(s.buf), fn)
+	}
+
+	if len(s.buf) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	s.buf = nil
+
+	m := &runMerger{}
+	defer m.close()
+	for _, path := range s.runs {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		r := &runReader{f: f, r: bufio.NewReaderSize(f, 64<<10)}
+		m.readers = append(m.readers, r)
+		if ok, err := r.next(); err != nil {
+			return err
+		} else if ok {
+			m.h = append(m.h, r)
+		}
+	}
+	heap.Init(&m.h)
+	return groupTileRefs(m.next, fn)
+}
+
+// groupTileRefs collects consecutive refs with the same tile ID.
+func groupTileRefs(next func() (tileRef, bool, error), fn func(uint64, []featureRef) error) error {
+	var group []featureRef
+	var current uint64
+	for {
+		r, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok || (len(group) > 0 && r.tileID != current) {
+			if len(group) > 0 {
+				if err := fn(current, group); err != nil {
+					return err
+				}
+			}
+			group = group[:0]
+		}
+		if !ok {
+			return nil
+		}
+		current = r.tileID
+		group = append(group, r.feature)
+	}
+}
+
+func sliceIter(refs []tileRef) func() (tileRef, bool, error) {
+	i := 0
+	return func() (tileRef, bool, error) {
+		if i >= len(refs) {
+			return tileRef{}, false, nil
+		}
+		i++
+		return refs[i-1], true, nil
+	}
+}
+
+func encodeTileRef(b []byte, r tileRef) {
+	binary.LittleEndian.PutUint64(b[0:8], r.tileID)
+	binary.LittleEndian.PutUint64(b[8:16], r.feature.offset)
+	binary.LittleEndian.PutUint32(b[16:20], r.feature.length)
+}
+
+func decodeTileRef(b []byte) tileRef {
+	return tileRef{
+		tileID: binary.LittleEndian.Uint64(b[0:8]),
+		feature: featureRef{
+			offset: binary.LittleEndian.Uint64(b[8:16]),
+			length: binary.LittleEndian.Uint32(b[16:20]),
+		},
+	}
+}
+
+// runReader reads tile refs sequentially from a sorted run file.
+type runReader struct {
+	f   *os.File
+	r   *bufio.Reader
+	cur tileRef
+}
+
+func (r *runReader) next() (bool, error) {
+	var rec [tileRefSize]byte
+	if _, err := io.ReadFull(r.r, rec[:]); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading sort run: %w", err)
+	}
+	r.cur = decodeTileRef(rec[:])
+	return true, nil
+}
+
+// runMerger performs a k-way merge of sorted run files.
+type runMerger struct {
+	readers []*runReader
+	h       runHeap
+}
+
+func (m *runMerger) next() (tileRef, bool, error) {
+	if len(m.h) == 0 {
+		return tileRef{}, false, nil
+	}
+	r := m.h[0]
+	out := r.cur
+	ok, err := r.next()
+	if err != nil {
+		return tileRef{}, false, err
+	}
+	if ok {
+		heap.Fix(&m.h, 0)
+	} else {
+		heap.Pop(&m.h)
+	}
+	return out, true, nil
+}
+
+func (m *runMerger) close() {
+	for _, r := range m.readers {
+		r.f.Close()
+	}
+}
+
+type runHeap []*runReader
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return h[i].cur.less(h[j].cur) }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
@@ -0,0 +1,268 @@
+package gotiler
+
+import (
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// maxAttributeValues caps the distinct values kept per attribute, matching
+// tippecanoe's tilestats output.
+const maxAttributeValues = 100
+
+// layerStats accumulates the schema and value statistics of one vector layer
+// for the TileJSON vector_layers and tilestats metadata.
+type layerStats struct {
+	name    string
+	minZoom int
+	maxZoom int
+
+	count      int
+	bound      orb.Bound
+	hasBound   bool
+	geometries map[string]int
+	attributes map[string]*attributeStats
+}
+
+// attributeStats tracks the values seen for one feature property.
+type attributeStats struct {
+	types    map[string]bool
+	values   map[any]bool
+	min, max float64
+	hasRange bool
+}
+
+func newLayerStats(name string, minZoom, maxZoom int) *layerStats {
+	return &layerStats{
+		name:       name,
+		minZoom:    minZoom,
+		maxZoom:    maxZoom,
+		geometries: make(map[string]int),
+		attributes: make(map[string]*attributeStats),
+	}
+}
+
+// add records a feature's geometry and properties.
+func (s *layerStats) add(f *geojson.Feature) {
+	if f.Geometry == nil {
+		return
+	}
+	s.count++
+	if s.hasBound {
+		s.bound = s.bound.Union(f.Geometry.Bound())
+	} else {
+		s.bound = f.Geometry.Bound()
+		s.hasBound = true
+	}
+	s.geometries[geometryKind(f.Geometry)]++
+
+	for k, v := range f.Properties {
+		s.attribute(k).add(v)
+	}
+}
+
+// declare records the type of a property generated while encoding tiles,
+// such as cluster counts, whose values are not sampled.
+func (s *layerStats) declare(name, typ string) {
+	s.attribute(name).types[typ] = true
+}
+
+func (s *layerStats) attribute(name string) *attributeStats {
+	a, ok := s.attributes[name]
+	if !ok {
+		a = &attributeStats{types: make(map[string]bool), values: make(map[any]bool)}
+		s.attributes[name] = a
+	}
+	return a
+}
+
+func (a *attributeStats) add(v any) {
+	switch val := v.(type) {
+	case nil:
+		return
+	case bool:
+		a.types["boolean"] = true
+	case float64:
+		a.types["number"] = true
+		if !a.hasRange {
+			a.min, a.max, a.hasRange = val, val, true
+		}
+		a.min = math.Min(a.min, val)
+		a.max = math.Max(a.max, val)
+	case string:
+		a.types["string"] = true
+	default:
+		// Nested objects and arrays are encoded as strings in MVT
+		a.types["string"] = true
+		return
+	}
+
+	if len(a.values) < maxAttributeValues {
+		a.values[v] = true
+	}
+}
+
+// fieldType returns the TileJSON field type: Number, String, Boolean or Mixed.
+func (a *attributeStats) fieldType() string {
+	if len(a.types) != 1 {
+		return "Mixed"
+	}
+	for t := range a.types {
+		switch t {
+		case "number":
+			return "Number"
+		case "boolean":
+			return "Boolean"
+		}
+	}
+	return "String"
+}
+
+// tilestatsType returns the mapbox-geostats attribute type.
+func (a *attributeStats) tilestatsType() string {
+	if len(a.types) != 1 {
+		return "mixed"
+	}
+	for t := range a.types {
+		return t
+	}
+	return "string"
+}
+
+// vectorLayer returns the TileJSON vector_layers entry for this layer.
+func (s *layerStats) vectorLayer() map[string]any {
+	fields := make(map[string]string, len(s.attributes))
+	for k, a := range s.attributes {
+		fields[k] = a.fieldType()
+	}
+	return map[string]any{
+		"id":      s.name,
+		"fields":  fields,
+		"minzoom": s.minZoom,
+		"maxzoom": s.maxZoom,
+	}
+}
+
+// tilestats returns the mapbox-geostats layer entry for this layer.
+func (s *layerStats) tilestats() map[string]any {
+	names := make([]string, 0, len(s.attributes))
+	for k := range s.attributes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	attributes := make([]map[string]any, 0, len(names))
+	for _, k := range names {
+		a := s.attributes[k]
+		values := make([]any, 0, len(a.values))
+		for v := range a.values {
+			values = append(values, v)
+		}
+		sortValues(values)
+
+		attr := map[string]any{
+			"attribute": k,
+			"count":     len(a.values),
+			"type":      a.tilestatsType(),
+			"values":    values,
+		}
+		if a.hasRange {
+			attr["min"] = a.min
+			attr["max"] = a.max
+		}
+		attributes = append(attributes, attr)
+	}
+
+	geometry := ""
+	best := 0
+	for g, n := range s.geometries {
+		if n > best || (n == best && g < geometry) {
+			geometry, best = g, n
+		}
+	}
+
+	return map[string]any{
+		"layer":          s.name,
+		"count":          s.count,
+		"geometry":       geometry,
+		"attributeCount": len(attributes),
+		"attributes":     attributes,
+	}
+}
+
+// sortValues orders attribute values deterministically: booleans, then
+// numbers, then strings.
+func sortValues(values []any) {
+	rank := func(v any) int {
+		switch v.(type) {
+		case bool:
+			return 0
+		case float64:
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(values, func(i, j int) bool {
+		ri, rj := rank(values[i]), rank(values[j])
+		if ri != rj {
+			return ri < rj
+		}
+		switch vi := values[i].(type) {
+		case bool:
+			return !vi && values[j].(bool)
+		case float64:
+			return vi < values[j].(float64)
+		case string:
+			return vi < values[j].(string)
+		}
+		return false
+	})
+}
+
+// geometryKind maps a geometry to the tilestats geometry names.
+func geometryKind(g orb.Geometry) string {
+	switch g.(type) {
+	case orb.Point, orb.MultiPoint:
+		return "Point"
+	case orb.LineString, orb.MultiLineString:
+		return "LineString"
+	case orb.Polygon, orb.MultiPolygon, orb.Ring:
+		return "Polygon"
+	}
+	return "Unknown"
+}
+
+// statsBound returns the union of the bounds of all layers.
+func statsBound(layers []*layerStats) orb.Bound {
+	var bound orb.Bound
+	first := true
+	for _, s := range layers {
+		if !s.hasBound {
+			continue
+		}
+		if first {
+			bound = s.bound
+			first = false
+			continue
+		}
+		bound = bound.Union(s.bound)
+	}
+	return bound
+}
+
+// layersMetadata adds vector_layers and tilestats entries to metadata.
+func layersMetadata(metadata map[string]any, layers []*layerStats) {
+	vectorLayers := make([]map[string]any, 0, len(layers))
+	statsLayers := make([]map[string]any, 0, len(layers))
+	for _, s := range layers {
+		vectorLayers = append(vectorLayers, s.vectorLayer())
+		statsLayers = append(statsLayers, s.tilestats())
+	}
+	metadata["vector_layers"] = vectorLayers
+	metadata["tilestats"] = map[string]any{
+		"layerCount": len(statsLayers),
+		"layers":     statsLayers,
+	}
+}
@@ -0,0 +1,221 @@
+package gotiler
+
+import (
+	"bufio"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/paulmach/orb"
+
+	"github.com/joeblew999/plat-geo/internal/pmtiles"
+	"github.com/joeblew999/plat-geo/internal/tiler"
+)
+
+// archiveWriter assembles a PMTiles archive from tiles added in ascending
+// tile ID order. Tile data is spooled to a temporary file so only the
+// directory entries and content hashes stay in memory.
+type archiveWriter struct {
+	data   *os.File
+	w      *bufio.Writer
+	offset uint64
+
+	entries        []pmtiles.EntryV3
+	contentOffsets map[[16]byte]uint64
+	hasher         hash.Hash
+	addressed      uint64
+	lastID         uint64
+}
+
+func newArchiveWriter(dir string) (*archiveWriter, error) {
+	f, err := os.CreateTemp(dir, "tiledata-*")
+	if err != nil {
+		return nil, err
+	}
+	return &archiveWriter{
+		data:           f,
+		w:              bufio.NewWriterSize(f, 1<<20),
+		contentOffsets: make(map[[16]byte]uint64),
+		hasher:         fnv.New128a(),
+	}, nil
+}
+
+// add appends a tile. Identical tile contents are stored once and shared
+// by offset; consecutive tile IDs with the same contents collapse into a
+// single run-length entry.
+func (a *archiveWriter) add(id uint64, data []byte) error {
+	if a.addressed > 0 && id <= a.lastID {
+		return fmt.Errorf("tile %d added out of order", id)
+	}
+	a.lastID = id
+	a.addressed++
+
+	a.hasher.Reset()
+	a.hasher.Write(data)
+	var sum [16]byte
+	copy(sum[:], a.hasher.Sum(nil))
+
+	offset, seen := a.contentOffsets[sum]
+	if !seen {
+		offset = a.offset
+		a.contentOffsets[sum] = offset
+		if _, err := a.w.Write(data); err != nil {
+			return fmt.Errorf("spooling tile data: %w", err)
+		}
+		a.offset += uint64(len(data))
+	}
+
+	if n := len(a.entries); n > 0 {
+		last := &a.entries[n-1]
+		if last.Offset == offset && last.TileID+uint64(last.RunLength) == id {
+			last.RunLength++
+			return nil
+		}
+	}
+	a.entries = append(a.entries, pmtiles.EntryV3{
+		TileID:    id,
+		Offset:    offset,
+		Length:    uint32(len(data)),
+		RunLength: 1,
+	})
+	return nil
+}
+
+// Close closes the spool file. The file itself is removed with the
+// tiler's temp directory.
+func (a *archiveWriter) Close() error {
+	return a.data.Close()
+}
+
+// writePMTiles writes the collected tiles to a PMTiles file.
+// PMTiles v3 format: https://github.com/protomaps/PMTiles/blob/main/spec/v3/spec.md
+func writePMTiles(path string, a *archiveWriter, layers []*layerStats, config tiler.TileConfig) error {
+	if len(a.entries) == 0 {
+		return fmt.Errorf("no tiles to write")
+	}
+	compression, err := pmtiles.ParseCompression(config.Compression)
+	if err != nil {
+		return err
+	}
+	if err := a.w.Flush(); err != nil {
+		return fmt.Errorf("spooling tile data: %w", err)
+	}
+
+	// Dataset extent and initial view
+	bound := statsBound(layers)
+	center := bound.Center()
+	cz := centerZoom(bound, config.MinZoom, config.MaxZoom)
+
+	// Build metadata JSON
+	metadata := map[string]any{
+		"name":        config.Layer,
+		"format":      "pbf",
+		"compression": compression.String(),
+		"minzoom":     config.MinZoom,
+		"maxzoom":     config.MaxZoom,
+		"bounds":      []float64{bound.Min.Lon(), bound.Min.Lat(), bound.Max.Lon(), bound.Max.Lat()},
+		"center":      []float64{center.Lon(), center.Lat(), float64(cz)},
+	}
+	layersMetadata(metadata, layers)
+	metadataBytes, err := pmtiles.SerializeMetadata(metadata, compression)
+	if err != nil {
+		return fmt.Errorf("serializing metadata: %w", err)
+	}
+
+	// Serialize directories with the tile compression, splitting into leaf
+	// directories when the root would exceed the 16 KiB initial fetch
+	rootDirBytes, leafDirBytes, _ := pmtiles.OptimizeDirectories(a.entries, pmtiles.RootDirectoryMaxLen, compression)
+
+	// Calculate offsets: header, root, metadata, leaves, tile data
+	headerSize := uint64(pmtiles.HeaderV3LenBytes)
+	rootDirOffset := headerSize
+	rootDirLen := uint64(len(rootDirBytes))
+	metadataOffset := rootDirOffset + rootDirLen
+	metadataLen := uint64(len(metadataBytes))
+	leafDirOffset := metadataOffset + metadataLen
+	leafDirLen := uint64(len(leafDirBytes))
+	tileDataOffset := leafDirOffset + leafDirLen
+	tileDataLen := a.offset
+
+	// Build header
+	header := pmtiles.HeaderV3{
+		SpecVersion:         3,
+		RootOffset:          rootDirOffset,
+		RootLength:          rootDirLen,
+		MetadataOffset:      metadataOffset,
+		MetadataLength:      metadataLen,
+		LeafDirectoryOffset: leafDirOffset,
+		LeafDirectoryLength: leafDirLen,
+		TileDataOffset:      tileDataOffset,
+		TileDataLength:      tileDataLen,
+		AddressedTilesCount: a.addressed,
+		TileEntriesCount:    uint64(len(a.entries)),
+		TileContentsCount:   uint64(len(a.contentOffsets)),
+		Clustered:           true,
+		InternalCompression: compression,
+		TileCompression:     compression,
+		TileType:            pmtiles.Mvt,
+		MinZoom:             uint8(config.MinZoom),
+		MaxZoom:             uint8(config.MaxZoom),
+		MinLonE7:            toE7(bound.Min.Lon()),
+		MinLatE7:            toE7(bound.Min.Lat()),
+		MaxLonE7:            toE7(bound.Max.Lon()),
+		MaxLatE7:            toE7(bound.Max.Lat()),
+		CenterZoom:          uint8(cz),
+		CenterLonE7:         toE7(center.Lon()),
+		CenterLatE7:         toE7(center.Lat()),
+	}
+
+	// Write to a temp file and rename it into place, so readers never see
+	// a partial archive and a failed write keeps the previous one
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	defer f.Close()
+	if err := f.Chmod(0644); err != nil {
+		return err
+	}
+
+	for _, section := range [][]byte{
+		pmtiles.SerializeHeader(header),
+		rootDirBytes,
+		metadataBytes,
+		leafDirBytes,
+	} {
+		if _, err := f.Write(section); err != nil {
+			return err
+		}
+	}
+
+	// Copy tile data from the spool file
+	if _, err := io.Copy(f, io.NewSectionReader(a.data, 0, int64(tileDataLen))); err != nil {
+		return fmt.Errorf("writing tile data: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// centerZoom picks the deepest zoom within [minZoom, maxZoom] at which the
+// bounds still fit in a 2x2 block of tiles, so viewers open on the data.
+func centerZoom(bound orb.Bound, minZoom, maxZoom int) int {
+	for z := maxZoom; z > minZoom; z-- {
+		if tileCount(bound, uint32(z)) <= 4 {
+			return z
+		}
+	}
+	return minZoom
+}
+
+// toE7 converts degrees to the fixed-point representation used in headers.
+func toE7(deg float64) int32 {
+	return int32(math.Round(deg * 1e7))
+}
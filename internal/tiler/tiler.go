@@ -1,6 +1,12 @@
 // Package tiler provides tile generation for geospatial data.
 package tiler
 
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
 // TileConfig holds settings for tile generation.
 type TileConfig struct {
 	MinZoom         int
@@ -10,13 +16,65 @@ type TileConfig struct {
 	NoFeatureLimit  bool   // Don't limit features per tile
 	NoTileSizeLimit bool   // Don't limit tile size
 	ReduceRate      int    // Feature reduction rate (tippecanoe -r flag, 0 = default)
+	Compression     string // Tile and directory compression: "gzip" (default), "brotli", "zstd", "none"
+	MemoryBudget    int64  // Bytes to buffer before spilling to disk (go engine, 0 = 256 MiB)
+	Workers         int    // Concurrent tile encoders (go engine, 0 = number of CPUs)
+
+	// Point clustering
+	ClusterDistance  int      // Merge points within this many pixels into one feature (0 = off)
+	ClusterMaxZoom   int      // Deepest zoom to cluster at (0 = one below MaxZoom)
+	ClusterAggregate []string // Numeric properties summed as <name>_sum, <name>_min, <name>_max on clusters (go engine)
+
+	// Attributes
+	Include          []string          // Keep only these properties (tippecanoe -y, empty = all)
+	Exclude          []string          // Drop these properties (tippecanoe -x)
+	AttributeTypes   map[string]string // Coerce properties to "string", "float", "int" or "bool" (tippecanoe -T)
+	AttributeMinZoom map[string]int    // Strip properties from tiles below these zooms (go engine)
+}
+
+// AttributeTypes lists the types properties can be coerced to.
+var AttributeTypes = []string{"string", "float", "int", "bool"}
+
+// ValidateAttributes checks the attribute settings of a config.
+func (c TileConfig) ValidateAttributes() error {
+	for name, typ := range c.AttributeTypes {
+		if !slices.Contains(AttributeTypes, typ) {
+			return fmt.Errorf("attribute %q: unknown type %q (use string, float, int or bool)", name, typ)
+		}
+	}
+	return nil
 }
 
+// LayerInput is one source tiled into a named layer of a multi-layer
+// archive. Config.Layer names the layer; its zoom range, point thinning,
+// clustering and attribute settings apply to this layer only.
+type LayerInput struct {
+	Path   string
+	Config TileConfig
+}
+
+// Progress reports how far tile generation has got.
+type Progress struct {
+	Zoom         int     // zoom of the tiles being written, -1 before any
+	TilesDone    int64   // tiles written so far
+	BytesWritten int64   // tile data bytes written so far
+	Percent      float64 // estimated completion, 0-100
+}
+
+// ProgressFunc receives progress updates. It is called from one goroutine
+// at a time and should return quickly.
+type ProgressFunc func(Progress)
+
 // Tiler generates PMTiles from GeoJSON.
 type Tiler interface {
-	// Tile converts a GeoJSON file to PMTiles.
-	// Returns the output path and any error.
-	Tile(inputPath, outputPath string, config TileConfig) error
+	// Tile converts a GeoJSON file to PMTiles. Cancelling ctx stops the
+	// run; progress, if not nil, receives periodic updates.
+	Tile(ctx context.Context, inputPath, outputPath string, config TileConfig, progress ProgressFunc) error
+
+	// TileLayers writes several sources into one PMTiles archive, one
+	// vector layer each. Archive-wide settings (name, compression, tile
+	// limits, memory budget and workers) come from config.
+	TileLayers(ctx context.Context, layers []LayerInput, outputPath string, config TileConfig, progress ProgressFunc) error
 
 	// Name returns the engine name (e.g., "tippecanoe", "go").
 	Name() string
@@ -2,8 +2,18 @@
 package tiler
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Tippecanoe implements Tiler using the tippecanoe CLI.
@@ -25,12 +35,175 @@ func (t *Tippecanoe) Available() bool {
 	return err == nil
 }
 
-// Tile converts GeoJSON to PMTiles using tippecanoe.
-func (t *Tippecanoe) Tile(inputPath, outputPath string, config TileConfig) error {
+// Tile converts GeoJSON to PMTiles using tippecanoe. Progress is parsed
+// from tippecanoe's status output; cancelling ctx kills the process.
+func (t *Tippecanoe) Tile(ctx context.Context, inputPath, outputPath string, config TileConfig, progress ProgressFunc) error {
 	if !t.Available() {
 		return fmt.Errorf("tippecanoe not found in PATH")
 	}
 
+	args, err := t.Args(inputPath, outputPath, config)
+	if err != nil {
+		return err
+	}
+
+	if err := runTool(ctx, "tippecanoe", args, progress); err != nil {
+		return fmt.Errorf("tippecanoe failed: %w", err)
+	}
+	reportDone(outputPath, progress)
+	return nil
+}
+
+// TileLayers tiles each layer separately with tippecanoe, using its own
+// zoom range and attribute settings, then merges the results with
+// tile-join.
+func (t *Tippecanoe) TileLayers(ctx context.Context, layers []LayerInput, outputPath string, config TileConfig, progress ProgressFunc) error {
+	if !t.Available() {
+		return fmt.Errorf("tippecanoe not found in PATH")
+	}
+	if _, err := exec.LookPath("tile-join"); err != nil {
+		return fmt.Errorf("tile-join not found in PATH")
+	}
+	if len(layers) == 0 {
+		return fmt.Errorf("no layers to tile")
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(outputPath), ".tippecanoe-*")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	args := []string{"-o", outputPath, "--force"}
+	if config.Layer != "" {
+		args = append(args, "--name="+config.Layer)
+	}
+	if config.NoTileSizeLimit {
+		args = append(args, "--no-tile-size-limit")
+	}
+	if config.Compression == "none" {
+		args = append(args, "--no-tile-compression")
+	}
+
+	for i, layer := range layers {
+		lc := layer.Config
+		lc.DropDensest = config.DropDensest
+		lc.NoFeatureLimit = config.NoFeatureLimit
+		lc.NoTileSizeLimit = config.NoTileSizeLimit
+		lc.Compression = config.Compression
+
+		// Layers share the first 90% of progress; tile-join the rest
+		var layerProgress ProgressFunc
+		if progress != nil {
+			layerProgress = func(p Progress) {
+				p.Percent = (float64(i)*100 + p.Percent) * 0.9 / float64(len(layers))
+				progress(p)
+			}
+		}
+
+		part := filepath.Join(tmpDir, fmt.Sprintf("layer-%d.pmtiles", i))
+		if err := t.Tile(ctx, layer.Path, part, lc, layerProgress); err != nil {
+			return fmt.Errorf("layer %q: %w", lc.Layer, err)
+		}
+		args = append(args, part)
+	}
+
+	if progress != nil {
+		progress(Progress{Zoom: -1, Percent: 90})
+	}
+	if err := runTool(ctx, "tile-join", args, nil); err != nil {
+		return fmt.Errorf("tile-join failed: %w", err)
+	}
+	reportDone(outputPath, progress)
+	return nil
+}
+
+// runTool runs a tippecanoe tool, passing the progress lines it writes to
+// stderr to progress. On failure the error includes its other stderr
+// output.
+func runTool(ctx context.Context, name string, args []string, progress ProgressFunc) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Status lines are rewritten in place with carriage returns
+	var output bytes.Buffer
+	scanner := bufio.NewScanner(stderr)
+	scanner.Split(scanStatusLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if p, ok := parseProgress(line); ok {
+			if progress != nil {
+				progress(p)
+			}
+		} else if line != "" {
+			output.WriteString(line + "\n")
+		}
+	}
+	io.Copy(io.Discard, stderr)
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w\nOutput: %s", err, output.Bytes())
+	}
+	return nil
+}
+
+// scanStatusLines splits output at newlines or carriage returns.
+func scanStatusLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// parseProgress parses a tippecanoe status line like "99.9%  11/327/791",
+// giving the overall percentage and the zoom of the tile being written.
+func parseProgress(line string) (Progress, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !strings.HasSuffix(fields[0], "%") {
+		return Progress{}, false
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+	if err != nil {
+		return Progress{}, false
+	}
+	p := Progress{Zoom: -1, Percent: pct}
+	if len(fields) > 1 {
+		if z, _, ok := strings.Cut(fields[1], "/"); ok {
+			if zoom, err := strconv.Atoi(z); err == nil {
+				p.Zoom = zoom
+			}
+		}
+	}
+	return p, true
+}
+
+// reportDone sends the final progress update with the archive size.
+func reportDone(outputPath string, progress ProgressFunc) {
+	if progress == nil {
+		return
+	}
+	p := Progress{Zoom: -1, Percent: 100}
+	if info, err := os.Stat(outputPath); err == nil {
+		p.BytesWritten = info.Size()
+	}
+	progress(p)
+}
+
+// Args returns the tippecanoe command-line arguments for a config, or an
+// error if the config uses settings tippecanoe does not support.
+func (t *Tippecanoe) Args(inputPath, outputPath string, config TileConfig) ([]string, error) {
 	args := []string{
 		"-o", outputPath,
 		"--force",
@@ -63,15 +236,47 @@ func (t *Tippecanoe) Tile(inputPath, outputPath string, config TileConfig) error
 		args = append(args, "--no-tile-size-limit")
 	}
 
-	args = append(args, inputPath)
+	// Point clustering
+	if config.ClusterDistance > 0 {
+		if len(config.ClusterAggregate) > 0 {
+			return nil, fmt.Errorf("tippecanoe does not support cluster aggregates (use the go engine)")
+		}
+		args = append(args, fmt.Sprintf("--cluster-distance=%d", config.ClusterDistance))
+		if config.ClusterMaxZoom > 0 {
+			args = append(args, fmt.Sprintf("--cluster-maxzoom=%d", config.ClusterMaxZoom))
+		} else if config.MaxZoom > 0 {
+			args = append(args, fmt.Sprintf("--cluster-maxzoom=%d", config.MaxZoom-1))
+		}
+	}
 
-	cmd := exec.Command("tippecanoe", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("tippecanoe failed: %w\nOutput: %s", err, output)
+	// Attributes
+	if err := config.ValidateAttributes(); err != nil {
+		return nil, err
+	}
+	if len(config.AttributeMinZoom) > 0 {
+		return nil, fmt.Errorf("tippecanoe does not support per-zoom attributes (use the go engine)")
+	}
+	for _, name := range config.Include {
+		args = append(args, "-y", name)
+	}
+	for _, name := range config.Exclude {
+		args = append(args, "-x", name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.AttributeTypes)) {
+		args = append(args, "-T", name+":"+config.AttributeTypes[name])
 	}
 
-	return nil
+	// Compression: tippecanoe only writes gzip or uncompressed tiles
+	switch config.Compression {
+	case "", "gzip":
+	case "none":
+		args = append(args, "--no-tile-compression")
+	default:
+		return nil, fmt.Errorf("tippecanoe does not support %q compression (use gzip or none, or the go engine)", config.Compression)
+	}
+
+	args = append(args, inputPath)
+	return args, nil
 }
 
 // Ensure Tippecanoe implements Tiler.
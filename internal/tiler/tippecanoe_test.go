@@ -0,0 +1,273 @@
+package tiler
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTippecanoeArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TileConfig
+		want   []string
+	}{
+		{
+			name:   "zoom range",
+			config: TileConfig{MinZoom: 2, MaxZoom: 12, Layer: "roads"},
+			want:   []string{"-o", "out.pmtiles", "--force", "--layer=roads", "-Z2", "-z12", "in.geojson"},
+		},
+		{
+			name:   "auto zoom",
+			config: TileConfig{MinZoom: -1, MaxZoom: -1},
+			want:   []string{"-o", "out.pmtiles", "--force", "-zg", "in.geojson"},
+		},
+		{
+			name:   "feature handling",
+			config: TileConfig{MaxZoom: 10, ReduceRate: 3, DropDensest: true, NoFeatureLimit: true, NoTileSizeLimit: true},
+			want: []string{"-o", "out.pmtiles", "--force", "-Z0", "-z10", "-r3",
+				"--drop-densest-as-needed", "--no-feature-limit", "--no-tile-size-limit", "in.geojson"},
+		},
+		{
+			name:   "attributes",
+			config: TileConfig{MaxZoom: 10, Include: []string{"name", "pop"}, Exclude: []string{"id"}, AttributeTypes: map[string]string{"pop": "int", "code": "string"}},
+			want: []string{"-o", "out.pmtiles", "--force", "-Z0", "-z10",
+				"-y", "name", "-y", "pop", "-x", "id", "-T", "code:string", "-T", "pop:int", "in.geojson"},
+		},
+		{
+			name:   "clustering below max zoom",
+			config: TileConfig{MaxZoom: 10, ClusterDistance: 20},
+			want:   []string{"-o", "out.pmtiles", "--force", "-Z0", "-z10", "--cluster-distance=20", "--cluster-maxzoom=9", "in.geojson"},
+		},
+		{
+			name:   "clustering to a zoom",
+			config: TileConfig{MaxZoom: 10, ClusterDistance: 20, ClusterMaxZoom: 6},
+			want:   []string{"-o", "out.pmtiles", "--force", "-Z0", "-z10", "--cluster-distance=20", "--cluster-maxzoom=6", "in.geojson"},
+		},
+		{
+			name:   "gzip",
+			config: TileConfig{MaxZoom: 4, Compression: "gzip"},
+			want:   []string{"-o", "out.pmtiles", "--force", "-Z0", "-z4", "in.geojson"},
+		},
+		{
+			name:   "uncompressed",
+			config: TileConfig{MaxZoom: 4, Compression: "none"},
+			want:   []string{"-o", "out.pmtiles", "--force", "-Z0", "-z4", "--no-tile-compression", "in.geojson"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := NewTippecanoe().Args("in.geojson", "out.pmtiles", tt.config)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTippecanoeArgsUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TileConfig
+		wantErr string
+	}{
+		{"cluster aggregates", TileConfig{ClusterDistance: 20, ClusterAggregate: []string{"pop"}}, "cluster aggregates"},
+		{"per-zoom attributes", TileConfig{AttributeMinZoom: map[string]int{"name": 8}}, "per-zoom attributes"},
+		{"unknown attribute type", TileConfig{AttributeTypes: map[string]string{"pop": "decimal"}}, "unknown type"},
+		{"brotli", TileConfig{Compression: "brotli"}, `"brotli" compression`},
+		{"zstd", TileConfig{Compression: "zstd"}, `"zstd" compression`},
+	}
+	for _, tt := range tests {
+		args, err := NewTippecanoe().Args("in.geojson", "out.pmtiles", tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: args %q, err %v; want an error containing %q", tt.name, args, err, tt.wantErr)
+		}
+	}
+}
+
+// fakeTool is a shell script standing in for tippecanoe or tile-join. It
+// logs its arguments, fails when FAKE_FAIL matches one of them, checks
+// that its .pmtiles inputs exist, and writes its -o output.
+const fakeTool = `#!/bin/sh
+echo "$(basename "$0") $*" >> "$FAKE_LOG"
+for arg in "$@"; do
+	if [ -n "$FAKE_FAIL" ] && [ "$arg" = "$FAKE_FAIL" ]; then
+		echo "fake failure" >&2
+		exit 1
+	fi
+done
+# Both tools take -o <output> first
+OUT="$2"
+shift 2
+for arg in "$@"; do
+	case "$arg" in
+	*.pmtiles) [ -f "$arg" ] || { echo "missing input $arg" >&2; exit 1; } ;;
+	esac
+done
+printf '50.0%%  3/1/2\r100.0%%  4/3/5\n' >&2
+echo archive > "$OUT"
+`
+
+// installFakeTools puts fake tippecanoe and tile-join first on PATH,
+// returning the file their invocations are logged to.
+func installFakeTools(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tools are shell scripts")
+	}
+	bin := t.TempDir()
+	for _, name := range []string{"tippecanoe", "tile-join"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(fakeTool), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	log := filepath.Join(t.TempDir(), "calls.log")
+	t.Setenv("FAKE_LOG", log)
+	return log
+}
+
+func TestTippecanoeTileLayers(t *testing.T) {
+	log := installFakeTools(t)
+	outDir := t.TempDir()
+	out := filepath.Join(outDir, "merged.pmtiles")
+
+	layers := []LayerInput{
+		{Path: "/data/roads.geojson", Config: TileConfig{Layer: "roads", MinZoom: 4, MaxZoom: 12, Include: []string{"name"}}},
+		{Path: "/data/pois.geojson", Config: TileConfig{Layer: "pois", MinZoom: 10, MaxZoom: 14, ClusterDistance: 30}},
+	}
+	config := TileConfig{Layer: "city", DropDensest: true, Compression: "none"}
+	var last Progress
+	err := NewTippecanoe().TileLayers(context.Background(), layers, out, config, func(p Progress) { last = p })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 3 {
+		t.Fatalf("calls:\n%s\nwant two tippecanoe runs and a tile-join", data)
+	}
+
+	// Each layer is tiled to its own part with its own settings, plus the
+	// archive-wide ones
+	tmpDir := regexp.QuoteMeta(outDir) + `/\.tippecanoe-[^/]+`
+	wants := []string{
+		`^tippecanoe -o (` + tmpDir + `/layer-0\.pmtiles) --force --layer=roads -Z4 -z12 --drop-densest-as-needed -y name --no-tile-compression /data/roads\.geojson$`,
+		`^tippecanoe -o (` + tmpDir + `/layer-1\.pmtiles) --force --layer=pois -Z10 -z14 --drop-densest-as-needed --cluster-distance=30 --cluster-maxzoom=13 --no-tile-compression /data/pois\.geojson$`,
+	}
+	var parts []string
+	for i, want := range wants {
+		m := regexp.MustCompile(want).FindStringSubmatch(calls[i])
+		if m == nil {
+			t.Fatalf("call %d:\n got %s\nwant %s", i, calls[i], want)
+		}
+		parts = append(parts, m[1])
+	}
+	wantJoin := "tile-join -o " + out + " --force --name=city --no-tile-compression " + strings.Join(parts, " ")
+	if calls[2] != wantJoin {
+		t.Errorf("tile-join call:\n got %s\nwant %s", calls[2], wantJoin)
+	}
+
+	// The per-layer parts are removed, leaving only the merged archive
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "merged.pmtiles" {
+		t.Errorf("output dir holds %v, want only merged.pmtiles", entries)
+	}
+	if last.Percent != 100 {
+		t.Errorf("last progress = %+v, want 100%%", last)
+	}
+}
+
+func TestTippecanoeTileLayersCleansUpOnFailure(t *testing.T) {
+	installFakeTools(t)
+	t.Setenv("FAKE_FAIL", "/data/pois.geojson")
+	outDir := t.TempDir()
+
+	layers := []LayerInput{
+		{Path: "/data/roads.geojson", Config: TileConfig{Layer: "roads", MaxZoom: 12}},
+		{Path: "/data/pois.geojson", Config: TileConfig{Layer: "pois", MaxZoom: 14}},
+	}
+	err := NewTippecanoe().TileLayers(context.Background(), layers, filepath.Join(outDir, "merged.pmtiles"), TileConfig{}, nil)
+	if err == nil || !strings.Contains(err.Error(), `layer "pois"`) || !strings.Contains(err.Error(), "fake failure") {
+		t.Fatalf("err = %v, want the pois layer's failure", err)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("output dir holds %v after a failed run, want nothing", entries)
+	}
+}
+
+func TestScanStatusLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"newlines", "For layer 0, using name \"roads\"\n1840 features, 96421 bytes of geometry\n", []string{`For layer 0, using name "roads"`, "1840 features, 96421 bytes of geometry"}},
+		{"carriage returns", "  0.2%  3/1/2  \r 45.1%  9/83/197  \r 99.9%  11/327/791  \r", []string{"  0.2%  3/1/2  ", " 45.1%  9/83/197  ", " 99.9%  11/327/791  "}},
+		{"mixed", "Read 0.45 million features\rRead 0.90 million features\n  1.0%  2/0/1  \r", []string{"Read 0.45 million features", "Read 0.90 million features", "  1.0%  2/0/1  "}},
+		{"partial line at EOF", " 12.5%  5/9/12  \r 50.0%  7/3", []string{" 12.5%  5/9/12  ", " 50.0%  7/3"}},
+		{"crlf", "done\r\n", []string{"done", ""}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		sc := bufio.NewScanner(strings.NewReader(tt.output))
+		sc.Split(scanStatusLines)
+		var got []string
+		for sc.Scan() {
+			got = append(got, sc.Text())
+		}
+		if err := sc.Err(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   Progress
+		wantOK bool
+	}{
+		{" 99.9%  11/327/791  ", Progress{Zoom: 11, Percent: 99.9}, true},
+		{"  0.0%  0/0/0  ", Progress{Zoom: 0, Percent: 0}, true},
+		{"100.0%  14/2620/6332", Progress{Zoom: 14, Percent: 100}, true},
+		{" 42.3%", Progress{Zoom: -1, Percent: 42.3}, true},
+		{" 12.5%  5/9", Progress{Zoom: 5, Percent: 12.5}, true},
+		{" 12.5%  z5", Progress{Zoom: -1, Percent: 12.5}, true},
+		{"Read 0.45 million features", Progress{}, false},
+		{"1840 features, 96421 bytes of geometry, 8 bytes of separate metadata, 24312 bytes of string pool", Progress{}, false},
+		{"Choosing a maxzoom of -z11 for features about 59 feet (18 meters) apart", Progress{}, false},
+		{"tile 11/327/791 size is 512004 with detail 12, >500000", Progress{}, false},
+		{"abc%  3/1/2", Progress{}, false},
+		{"", Progress{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseProgress(tt.line)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseProgress(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // CreatedLayerBody represents the CreatedLayerBody schema
@@ -57,6 +58,27 @@ type InfoBody struct {
 	Version  string   `json:"version" doc:"Service version"`
 }
 
+// JobBody represents the JobBody schema
+type JobBody struct {
+	Attempts   int64      `json:"attempts" doc:"Times the job has been started" format:"int64"`
+	CreatedAt  time.Time  `json:"createdAt" doc:"When the job was submitted" format:"date-time"`
+	Error      string     `json:"error,omitempty" doc:"Failure reason"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty" doc:"When the latest attempt finished" format:"date-time"`
+	ID         string     `json:"id" doc:"Job ID" example:"20260101-120000-a1b2c3"`
+	Logs       []string   `json:"logs" doc:"Timestamped log lines, oldest first"`
+	Progress   int64      `json:"progress" doc:"Progress percentage" minimum:"0" maximum:"100" format:"int64"`
+	Request    JobRequest `json:"request" doc:"Requested work"`
+	StartedAt  *time.Time `json:"startedAt,omitempty" doc:"When the latest attempt started" format:"date-time"`
+	State      string     `json:"state" doc:"Job state" enum:"queued,running,succeeded,failed,cancelled"`
+	Status     string     `json:"status,omitempty" doc:"Latest status message"`
+}
+
+// JobRequest represents the JobRequest schema
+type JobRequest struct {
+	Generate *TileGenerateOptions `json:"generate,omitempty" doc:"Generate tiles from one source file"`
+	Layers   *TileLayersOptions   `json:"layers,omitempty" doc:"Generate a multi-layer tileset from several source files"`
+}
+
 // JSONPatchOp represents the JsonPatchOp schema
 type JSONPatchOp struct {
 	From  string `json:"from,omitempty" doc:"JSON Pointer for the source of a move or copy"`
@@ -80,6 +102,7 @@ type LayerBody struct {
 	RenderRules    []RenderRule `json:"renderRules,omitempty" doc:"Conditional styling rules"`
 	Stroke         string       `json:"stroke,omitempty" doc:"Stroke color (CSS)" default:"#2266cc" example:"#2266cc"`
 	Styles         []Style      `json:"styles,omitempty" doc:"Named style variants"`
+	Version        string       `json:"version,omitempty" doc:"Pinned tileset version; empty follows the current one" example:"3f9a1c2b7d4e"`
 }
 
 // LayerConfig represents the LayerConfig schema
@@ -97,6 +120,7 @@ type LayerConfig struct {
 	RenderRules    []RenderRule `json:"renderRules,omitempty" doc:"Conditional styling rules"`
 	Stroke         string       `json:"stroke,omitempty" doc:"Stroke color (CSS)" default:"#2266cc" example:"#2266cc"`
 	Styles         []Style      `json:"styles,omitempty" doc:"Named style variants"`
+	Version        string       `json:"version,omitempty" doc:"Pinned tileset version; empty follows the current one" example:"3f9a1c2b7d4e"`
 }
 
 // LegendItem represents the LegendItem schema
@@ -110,6 +134,14 @@ type MessageBody struct {
 	Message string `json:"message" doc:"Result message"`
 }
 
+// PageBodyJobBody represents the PageBodyJobBody schema
+type PageBodyJobBody struct {
+	Data   []JobBody `json:"data" doc:"Items"`
+	Limit  int64     `json:"limit" doc:"Page size" format:"int64"`
+	Offset int64     `json:"offset" doc:"Current offset" format:"int64"`
+	Total  int64     `json:"total" doc:"Total number of items" format:"int64"`
+}
+
 // PageBodySourceFile represents the PageBodySourceFile schema
 type PageBodySourceFile struct {
 	Data   []SourceFile `json:"data" doc:"Items"`
@@ -126,6 +158,14 @@ type PageBodyTileFile struct {
 	Total  int64      `json:"total" doc:"Total number of items" format:"int64"`
 }
 
+// PageBodyTileVersionBody represents the PageBodyTileVersionBody schema
+type PageBodyTileVersionBody struct {
+	Data   []TileVersionBody `json:"data" doc:"Items"`
+	Limit  int64             `json:"limit" doc:"Page size" format:"int64"`
+	Offset int64             `json:"offset" doc:"Current offset" format:"int64"`
+	Total  int64             `json:"total" doc:"Total number of items" format:"int64"`
+}
+
 // PostAPIV1QueryRequest represents the Post-api-v1-queryRequest schema
 type PostAPIV1QueryRequest struct {
 	Query string `json:"query" doc:"SQL query to execute"`
@@ -138,6 +178,11 @@ type QueryBody struct {
 	Rows    []map[string]any `json:"rows" doc:"Query results"`
 }
 
+// RenameTileInput represents the RenameTileInput schema
+type RenameTileInput struct {
+	Name string `json:"name" doc:"New PMTiles file name" minLength:"1" maxLength:"200" example:"buildings-2024.pmtiles"`
+}
+
 // RenderRule represents the RenderRule schema
 type RenderRule struct {
 	Fill        string  `json:"fill" doc:"Fill color (CSS)"`
@@ -151,7 +196,7 @@ type RenderRule struct {
 
 // SourceFile represents the SourceFile schema
 type SourceFile struct {
-	FileType string `json:"fileType" doc:"File type: GeoJSON or GeoParquet" example:"GeoJSON"`
+	FileType string `json:"fileType" doc:"File type: GeoJSON, GeoJSONSeq, GeoParquet or FlatGeobuf" example:"GeoJSON"`
 	Name     string `json:"name" doc:"File name" example:"buildings.geojson"`
 	Size     string `json:"size" doc:"Human-readable file size" example:"1.2 MB"`
 }
@@ -171,8 +216,133 @@ type TablesBody struct {
 
 // TileFile represents the TileFile schema
 type TileFile struct {
-	Name string `json:"name" doc:"PMTiles file name" example:"buildings.pmtiles"`
-	Size string `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	AddressedTiles int64       `json:"addressedTiles" doc:"Number of tiles addressed by the directory" minimum:"0" format:"int64"`
+	Bounds         []float64   `json:"bounds,omitempty" doc:"Bounds as west, south, east, north" example:"[-122.5 37.7 -122.3 37.8]"`
+	Bytes          int64       `json:"bytes" doc:"File size in bytes" format:"int64" example:"5.66231e+06"`
+	Center         []float64   `json:"center,omitempty" doc:"Default view as longitude, latitude, zoom" example:"[-122.4 37.75 10]"`
+	Compression    string      `json:"compression,omitempty" doc:"Tile compression" example:"gzip"`
+	Error          string      `json:"error,omitempty" doc:"Why the archive could not be read"`
+	Layers         []TileLayer `json:"layers,omitempty" doc:"Vector layers in the tiles"`
+	MaxZoom        int64       `json:"maxZoom" doc:"Maximum zoom level" format:"int64" example:"14"`
+	MinZoom        int64       `json:"minZoom" doc:"Minimum zoom level" format:"int64" example:"0"`
+	ModTime        time.Time   `json:"modTime" doc:"Last modification time" format:"date-time"`
+	Name           string      `json:"name" doc:"PMTiles file name" example:"buildings.pmtiles"`
+	Size           string      `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	TileContents   int64       `json:"tileContents" doc:"Number of distinct tile contents" minimum:"0" format:"int64"`
+	TileEntries    int64       `json:"tileEntries" doc:"Number of directory entries, after run-length encoding" minimum:"0" format:"int64"`
+	TileType       string      `json:"tileType,omitempty" doc:"Tile format" example:"mvt"`
+}
+
+// TileFileBody represents the TileFileBody schema
+type TileFileBody struct {
+	AddressedTiles int64       `json:"addressedTiles" doc:"Number of tiles addressed by the directory" minimum:"0" format:"int64"`
+	Bounds         []float64   `json:"bounds,omitempty" doc:"Bounds as west, south, east, north" example:"[-122.5 37.7 -122.3 37.8]"`
+	Bytes          int64       `json:"bytes" doc:"File size in bytes" format:"int64" example:"5.66231e+06"`
+	Center         []float64   `json:"center,omitempty" doc:"Default view as longitude, latitude, zoom" example:"[-122.4 37.75 10]"`
+	Compression    string      `json:"compression,omitempty" doc:"Tile compression" example:"gzip"`
+	Error          string      `json:"error,omitempty" doc:"Why the archive could not be read"`
+	Layers         []TileLayer `json:"layers,omitempty" doc:"Vector layers in the tiles"`
+	MaxZoom        int64       `json:"maxZoom" doc:"Maximum zoom level" format:"int64" example:"14"`
+	MinZoom        int64       `json:"minZoom" doc:"Minimum zoom level" format:"int64" example:"0"`
+	ModTime        time.Time   `json:"modTime" doc:"Last modification time" format:"date-time"`
+	Name           string      `json:"name" doc:"PMTiles file name" example:"buildings.pmtiles"`
+	Size           string      `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	TileContents   int64       `json:"tileContents" doc:"Number of distinct tile contents" minimum:"0" format:"int64"`
+	TileEntries    int64       `json:"tileEntries" doc:"Number of directory entries, after run-length encoding" minimum:"0" format:"int64"`
+	TileType       string      `json:"tileType,omitempty" doc:"Tile format" example:"mvt"`
+}
+
+// TileGenerateOptions represents the TileGenerateOptions schema
+type TileGenerateOptions struct {
+	AttributeMinZoom map[string]any `json:"attributeMinZoom,omitempty" doc:"Strip properties from tiles below these zooms (go engine only)"`
+	AttributeTypes   map[string]any `json:"attributeTypes,omitempty" doc:"Coerce properties to string, float, int or bool"`
+	Engine           string         `json:"engine,omitempty" doc:"Tiler engine; auto uses tippecanoe when installed and able, else go" enum:"auto,tippecanoe,go" default:"auto"`
+	Exclude          []string       `json:"exclude,omitempty" doc:"Drop these properties from tiles"`
+	Include          []string       `json:"include,omitempty" doc:"Only keep these properties in tiles"`
+	LayerName        string         `json:"layerName" doc:"Layer name in tiles"`
+	MaxZoom          int64          `json:"maxZoom" doc:"Maximum zoom level" minimum:"0" maximum:"22" format:"int64"`
+	MinZoom          int64          `json:"minZoom" doc:"Minimum zoom level" minimum:"0" maximum:"22" format:"int64"`
+	OutputName       string         `json:"outputName" doc:"Output PMTiles name"`
+	SourceFile       string         `json:"sourceFile" doc:"Source file name"`
+}
+
+// TileJSONBody represents the TileJSONBody schema
+type TileJSONBody struct {
+	Attribution  string        `json:"attribution,omitempty" doc:"Attribution to display on the map"`
+	Bounds       []float64     `json:"bounds" doc:"Bounds as west, south, east, north" example:"[-180 -85.0511 180 85.0511]"`
+	Center       []float64     `json:"center" doc:"Default view as longitude, latitude, zoom" example:"[0 0 2]"`
+	Description  string        `json:"description,omitempty" doc:"Tileset description"`
+	Maxzoom      int64         `json:"maxzoom" doc:"Maximum zoom level" format:"int64" example:"14"`
+	Minzoom      int64         `json:"minzoom" doc:"Minimum zoom level" format:"int64" example:"0"`
+	Name         string        `json:"name,omitempty" doc:"Tileset name" example:"buildings"`
+	Scheme       string        `json:"scheme" doc:"Tile coordinate scheme" example:"xyz"`
+	Tilejson     string        `json:"tilejson" doc:"TileJSON spec version" example:"3.0.0"`
+	Tiles        []string      `json:"tiles" doc:"Tile URL templates"`
+	VectorLayers []VectorLayer `json:"vector_layers,omitempty" doc:"Vector layers in the tiles"`
+	Version      string        `json:"version,omitempty" doc:"Tileset version" example:"2"`
+}
+
+// TileLayer represents the TileLayer schema
+type TileLayer struct {
+	GeomType string `json:"geomType,omitempty" doc:"Dominant geometry type, when known" enum:"polygon,line,point" example:"polygon"`
+	Name     string `json:"name" doc:"Layer name" example:"buildings"`
+}
+
+// TileLayerOptions represents the TileLayerOptions schema
+type TileLayerOptions struct {
+	AttributeMinZoom map[string]any `json:"attributeMinZoom,omitempty" doc:"Strip properties from tiles below these zooms (go engine only)"`
+	AttributeTypes   map[string]any `json:"attributeTypes,omitempty" doc:"Coerce properties to string, float, int or bool"`
+	Exclude          []string       `json:"exclude,omitempty" doc:"Drop these properties from tiles"`
+	Include          []string       `json:"include,omitempty" doc:"Only keep these properties in tiles"`
+	LayerName        string         `json:"layerName" doc:"Layer name in tiles"`
+	MaxZoom          int64          `json:"maxZoom" doc:"Maximum zoom level" minimum:"0" maximum:"22" format:"int64"`
+	MinZoom          int64          `json:"minZoom" doc:"Minimum zoom level" minimum:"0" maximum:"22" format:"int64"`
+	SourceFile       string         `json:"sourceFile" doc:"Source file name"`
+}
+
+// TileLayersOptions represents the TileLayersOptions schema
+type TileLayersOptions struct {
+	Engine     string             `json:"engine,omitempty" doc:"Tiler engine; auto uses tippecanoe when installed and able, else go" enum:"auto,tippecanoe,go" default:"auto"`
+	Layers     []TileLayerOptions `json:"layers" doc:"Layers to include, one per source file" minItems:"1"`
+	OutputName string             `json:"outputName" doc:"Output PMTiles name"`
+}
+
+// TileVerificationBody represents the TileVerificationBody schema
+type TileVerificationBody struct {
+	Entries  int64    `json:"entries" doc:"Tile directory entries checked" minimum:"0" format:"int64" example:"1250"`
+	Name     string   `json:"name" doc:"Tileset file name" example:"buildings.pmtiles"`
+	Problems []string `json:"problems" doc:"Problems found, empty when valid"`
+	Tiles    int64    `json:"tiles" doc:"Distinct tiles decoded" minimum:"0" format:"int64" example:"1180"`
+	Valid    bool     `json:"valid" doc:"Whether no problems were found"`
+}
+
+// TileVersionBody represents the TileVersionBody schema
+type TileVersionBody struct {
+	CreatedAt time.Time `json:"createdAt" doc:"When the version was generated" format:"date-time"`
+	Current   bool      `json:"current" doc:"Whether the version is the one being served"`
+	Engine    string    `json:"engine,omitempty" doc:"Tiler engine that generated the version" example:"go"`
+	ID        string    `json:"id" doc:"Content hash of the archive" example:"3f9a1c2b7d4e"`
+	Options   any       `json:"options,omitempty" doc:"Generation options"`
+	PinnedBy  []string  `json:"pinnedBy,omitempty" doc:"IDs of layers pinned to this version"`
+	Size      string    `json:"size" doc:"Human-readable file size" example:"5.4 MB"`
+	Sources   []string  `json:"sources,omitempty" doc:"Source files the version was generated from" example:"[buildings.geojson]"`
+	URL       string    `json:"url" doc:"URL of the version's archive" example:"/tiles/versions/buildings/3f9a1c2b7d4e.pmtiles"`
+}
+
+// TilerEngine represents the TilerEngine schema
+type TilerEngine struct {
+	Available bool   `json:"available" doc:"Whether the engine can be used"`
+	Default   bool   `json:"default" doc:"Whether auto selects this engine when settings allow"`
+	Name      string `json:"name" doc:"Engine name" example:"tippecanoe"`
+}
+
+// VectorLayer represents the VectorLayer schema
+type VectorLayer struct {
+	Description string         `json:"description,omitempty" doc:"Layer description"`
+	Fields      map[string]any `json:"fields" doc:"Attribute names and types"`
+	ID          string         `json:"id" doc:"Layer name" example:"buildings"`
+	Maxzoom     int64          `json:"maxzoom" doc:"Maximum zoom level of the layer" format:"int64"`
+	Minzoom     int64          `json:"minzoom" doc:"Minimum zoom level of the layer" format:"int64"`
 }
 
 // Option is a functional option for customizing requests
@@ -240,14 +410,14 @@ func WithOptions(applier OptionsApplier) Option {
 	}
 }
 
-// GetAPIV1SourcesOptions contains optional parameters for GetAPIV1Sources
-type GetAPIV1SourcesOptions struct {
+// GetAPIV1JobsOptions contains optional parameters for GetAPIV1Jobs
+type GetAPIV1JobsOptions struct {
 	Limit  int64 `json:"limit,omitempty"`
 	Offset int64 `json:"offset,omitempty"`
 }
 
-// Apply implements OptionsApplier for GetAPIV1SourcesOptions
-func (o GetAPIV1SourcesOptions) Apply(opts *RequestOptions) {
+// Apply implements OptionsApplier for GetAPIV1JobsOptions
+func (o GetAPIV1JobsOptions) Apply(opts *RequestOptions) {
 	if o.Limit != 0 {
 		if opts.CustomQuery == nil {
 			opts.CustomQuery = make(map[string]string)
@@ -262,6 +432,51 @@ func (o GetAPIV1SourcesOptions) Apply(opts *RequestOptions) {
 	}
 }
 
+// PostAPIV1TilesOptions contains optional parameters for PostAPIV1Tiles
+type PostAPIV1TilesOptions struct {
+	ContentType string `json:"Content-Type,omitempty"`
+}
+
+// Apply implements OptionsApplier for PostAPIV1TilesOptions
+func (o PostAPIV1TilesOptions) Apply(opts *RequestOptions) {
+	if o.ContentType != "" {
+		if opts.CustomHeaders == nil {
+			opts.CustomHeaders = make(map[string]string)
+		}
+		opts.CustomHeaders["Content-Type"] = o.ContentType
+	}
+}
+
+// DeleteAPIV1TilesByNameOptions contains optional parameters for DeleteAPIV1TilesByName
+type DeleteAPIV1TilesByNameOptions struct {
+	Force bool `json:"force,omitempty"`
+}
+
+// Apply implements OptionsApplier for DeleteAPIV1TilesByNameOptions
+func (o DeleteAPIV1TilesByNameOptions) Apply(opts *RequestOptions) {
+	if o.Force {
+		if opts.CustomQuery == nil {
+			opts.CustomQuery = make(map[string]string)
+		}
+		opts.CustomQuery["force"] = fmt.Sprintf("%v", o.Force)
+	}
+}
+
+// GetAPIV1TilesByNameTilejsonJSONOptions contains optional parameters for GetAPIV1TilesByNameTilejsonJSON
+type GetAPIV1TilesByNameTilejsonJSONOptions struct {
+	XForwardedProto string `json:"X-Forwarded-Proto,omitempty"`
+}
+
+// Apply implements OptionsApplier for GetAPIV1TilesByNameTilejsonJSONOptions
+func (o GetAPIV1TilesByNameTilejsonJSONOptions) Apply(opts *RequestOptions) {
+	if o.XForwardedProto != "" {
+		if opts.CustomHeaders == nil {
+			opts.CustomHeaders = make(map[string]string)
+		}
+		opts.CustomHeaders["X-Forwarded-Proto"] = o.XForwardedProto
+	}
+}
+
 // PlatGeoAPIClient defines the interface for the API client
 type PlatGeoAPIClient interface {
 	GetAPIV1EditorEvents(ctx context.Context, opts ...Option) (*http.Response, error)
@@ -273,9 +488,16 @@ type PlatGeoAPIClient interface {
 	PostAPIV1EditorSourcesUpload(ctx context.Context, opts ...Option) (*http.Response, error)
 	DeleteAPIV1EditorSourcesByFilename(ctx context.Context, filename string, opts ...Option) (*http.Response, error)
 	GetAPIV1EditorTiles(ctx context.Context, opts ...Option) (*http.Response, error)
+	PostAPIV1EditorTilesAutofill(ctx context.Context, opts ...Option) (*http.Response, error)
 	PostAPIV1EditorTilesGenerate(ctx context.Context, opts ...Option) (*http.Response, error)
 	GetAPIV1EditorTilesSelect(ctx context.Context, opts ...Option) (*http.Response, error)
+	ListAPIV1Engines(ctx context.Context, opts ...Option) (*http.Response, []TilerEngine, error)
 	GetAPIV1Info(ctx context.Context, opts ...Option) (*http.Response, InfoBody, error)
+	GetAPIV1Jobs(ctx context.Context, opts ...Option) (*http.Response, PageBodyJobBody, error)
+	PostAPIV1Jobs(ctx context.Context, body JobRequest, opts ...Option) (*http.Response, JobBody, error)
+	GetAPIV1JobsByID(ctx context.Context, id string, opts ...Option) (*http.Response, JobBody, error)
+	PostAPIV1JobsByIDCancel(ctx context.Context, id string, opts ...Option) (*http.Response, JobBody, error)
+	PostAPIV1JobsByIDRetry(ctx context.Context, id string, opts ...Option) (*http.Response, JobBody, error)
 	GetAPIV1Layers(ctx context.Context, opts ...Option) (*http.Response, map[string]any, error)
 	PostAPIV1Layers(ctx context.Context, body LayerConfig, opts ...Option) (*http.Response, CreatedLayerBody, error)
 	GetAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error)
@@ -292,6 +514,14 @@ type PlatGeoAPIClient interface {
 	GetAPIV1Sources(ctx context.Context, opts ...Option) (*http.Response, PageBodySourceFile, error)
 	GetAPIV1Tables(ctx context.Context, opts ...Option) (*http.Response, TablesBody, error)
 	GetAPIV1Tiles(ctx context.Context, opts ...Option) (*http.Response, PageBodyTileFile, error)
+	PostAPIV1Tiles(ctx context.Context, opts ...Option) (*http.Response, TileFileBody, error)
+	GetAPIV1TilesByName(ctx context.Context, name string, opts ...Option) (*http.Response, TileFileBody, error)
+	DeleteAPIV1TilesByName(ctx context.Context, name string, opts ...Option) (*http.Response, MessageBody, error)
+	PostAPIV1TilesByNameRename(ctx context.Context, name string, body RenameTileInput, opts ...Option) (*http.Response, TileFileBody, error)
+	GetAPIV1TilesByNameTilejsonJSON(ctx context.Context, name string, opts ...Option) (*http.Response, TileJSONBody, error)
+	PostAPIV1TilesByNameVerify(ctx context.Context, name string, opts ...Option) (*http.Response, TileVerificationBody, error)
+	GetAPIV1TilesByNameVersions(ctx context.Context, name string, opts ...Option) (*http.Response, PageBodyTileVersionBody, error)
+	PostAPIV1TilesByNameVersionsByVersionRollback(ctx context.Context, name string, version string, opts ...Option) (*http.Response, TileVersionBody, error)
 	GetHealth(ctx context.Context, opts ...Option) (*http.Response, HealthBody, error)
 	Follow(ctx context.Context, link string, result any, opts ...Option) (*http.Response, error)
 }
@@ -734,8 +964,8 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1EditorTiles(ctx context.Context, opts ...
 	return resp, nil
 }
 
-// PostAPIV1EditorTilesGenerate calls the POST /api/v1/editor/tiles/generate endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1EditorTilesGenerate(ctx context.Context, opts ...Option) (*http.Response, error) {
+// PostAPIV1EditorTilesAutofill calls the POST /api/v1/editor/tiles/autofill endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1EditorTilesAutofill(ctx context.Context, opts ...Option) (*http.Response, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -743,7 +973,7 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1EditorTilesGenerate(ctx context.Context,
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/editor/tiles/generate"
+	pathTemplate := "/api/v1/editor/tiles/autofill"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
@@ -780,8 +1010,8 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1EditorTilesGenerate(ctx context.Context,
 	return resp, nil
 }
 
-// GetAPIV1EditorTilesSelect calls the GET /api/v1/editor/tiles/select endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1EditorTilesSelect(ctx context.Context, opts ...Option) (*http.Response, error) {
+// PostAPIV1EditorTilesGenerate calls the POST /api/v1/editor/tiles/generate endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1EditorTilesGenerate(ctx context.Context, opts ...Option) (*http.Response, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -789,7 +1019,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1EditorTilesSelect(ctx context.Context, op
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/editor/tiles/select"
+	pathTemplate := "/api/v1/editor/tiles/generate"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
@@ -803,7 +1033,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1EditorTilesSelect(ctx context.Context, op
 	var reqBody io.Reader
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -826,8 +1056,8 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1EditorTilesSelect(ctx context.Context, op
 	return resp, nil
 }
 
-// GetAPIV1Info calls the GET /api/v1/info endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1Info(ctx context.Context, opts ...Option) (*http.Response, InfoBody, error) {
+// GetAPIV1EditorTilesSelect calls the GET /api/v1/editor/tiles/select endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1EditorTilesSelect(ctx context.Context, opts ...Option) (*http.Response, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -835,11 +1065,11 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Info(ctx context.Context, opts ...Option)
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/info"
+	pathTemplate := "/api/v1/editor/tiles/select"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, InfoBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -851,7 +1081,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Info(ctx context.Context, opts ...Option)
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, InfoBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -860,26 +1090,20 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Info(ctx context.Context, opts ...Option)
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, InfoBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, InfoBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
-	}
-	// Parse response body
-	var result InfoBody
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, InfoBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
-
-	return resp, result, nil
+	return resp, nil
 }
 
-// GetAPIV1Layers calls the GET /api/v1/layers endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1Layers(ctx context.Context, opts ...Option) (*http.Response, map[string]any, error) {
+// ListAPIV1Engines calls the GET /api/v1/engines endpoint
+func (c *PlatGeoAPIClientImpl) ListAPIV1Engines(ctx context.Context, opts ...Option) (*http.Response, []TilerEngine, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -887,7 +1111,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Layers(ctx context.Context, opts ...Optio
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers"
+	pathTemplate := "/api/v1/engines"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
@@ -922,7 +1146,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Layers(ctx context.Context, opts ...Optio
 		return resp, nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result map[string]any
+	var result []TilerEngine
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return resp, nil, fmt.Errorf("failed to decode response: %w", err)
 	}
@@ -930,8 +1154,8 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Layers(ctx context.Context, opts ...Optio
 	return resp, result, nil
 }
 
-// PostAPIV1Layers calls the POST /api/v1/layers endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1Layers(ctx context.Context, body LayerConfig, opts ...Option) (*http.Response, CreatedLayerBody, error) {
+// GetAPIV1Info calls the GET /api/v1/info endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1Info(ctx context.Context, opts ...Option) (*http.Response, InfoBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -939,11 +1163,11 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1Layers(ctx context.Context, body LayerCo
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers"
+	pathTemplate := "/api/v1/info"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, InfoBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -951,47 +1175,39 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1Layers(ctx context.Context, body LayerCo
 
 	// Prepare request body
 	var reqBody io.Reader
-	jsonData, err := json.Marshal(body)
-	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, InfoBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
-	if reqBody != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
 	reqOpts.applyHeaders(req)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, InfoBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, CreatedLayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, InfoBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result CreatedLayerBody
+	var result InfoBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, CreatedLayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, InfoBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// GetAPIV1LayersByID calls the GET /api/v1/layers/{id} endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
+// GetAPIV1Jobs calls the GET /api/v1/jobs endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1Jobs(ctx context.Context, opts ...Option) (*http.Response, PageBodyJobBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -999,12 +1215,11 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1LayersByID(ctx context.Context, id string
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}"
-	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+	pathTemplate := "/api/v1/jobs"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, PageBodyJobBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1016,7 +1231,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1LayersByID(ctx context.Context, id string
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, PageBodyJobBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1025,26 +1240,26 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1LayersByID(ctx context.Context, id string
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, PageBodyJobBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, PageBodyJobBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result LayerBody
+	var result PageBodyJobBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, PageBodyJobBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// PutAPIV1LayersByID calls the PUT /api/v1/layers/{id} endpoint
-func (c *PlatGeoAPIClientImpl) PutAPIV1LayersByID(ctx context.Context, id string, body LayerConfig, opts ...Option) (*http.Response, LayerBody, error) {
+// PostAPIV1Jobs calls the POST /api/v1/jobs endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1Jobs(ctx context.Context, body JobRequest, opts ...Option) (*http.Response, JobBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1052,12 +1267,11 @@ func (c *PlatGeoAPIClientImpl) PutAPIV1LayersByID(ctx context.Context, id string
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}"
-	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+	pathTemplate := "/api/v1/jobs"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, JobBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1067,14 +1281,14 @@ func (c *PlatGeoAPIClientImpl) PutAPIV1LayersByID(ctx context.Context, id string
 	var reqBody io.Reader
 	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, JobBody{}, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "PUT", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, JobBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1086,26 +1300,26 @@ func (c *PlatGeoAPIClientImpl) PutAPIV1LayersByID(ctx context.Context, id string
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, JobBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, JobBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result LayerBody
+	var result JobBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, JobBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// DeleteAPIV1LayersByID calls the DELETE /api/v1/layers/{id} endpoint
-func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, MessageBody, error) {
+// GetAPIV1JobsByID calls the GET /api/v1/jobs/{id} endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1JobsByID(ctx context.Context, id string, opts ...Option) (*http.Response, JobBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1113,12 +1327,12 @@ func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByID(ctx context.Context, id str
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}"
+	pathTemplate := "/api/v1/jobs/{id}"
 	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, MessageBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, JobBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1128,9 +1342,9 @@ func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByID(ctx context.Context, id str
 	var reqBody io.Reader
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "DELETE", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, MessageBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, JobBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1139,26 +1353,26 @@ func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByID(ctx context.Context, id str
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, MessageBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, JobBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, MessageBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, JobBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result MessageBody
+	var result JobBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, MessageBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, JobBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// PatchAPIV1LayersByID calls the PATCH /api/v1/layers/{id} endpoint
-func (c *PlatGeoAPIClientImpl) PatchAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
+// PostAPIV1JobsByIDCancel calls the POST /api/v1/jobs/{id}/cancel endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1JobsByIDCancel(ctx context.Context, id string, opts ...Option) (*http.Response, JobBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1166,12 +1380,12 @@ func (c *PlatGeoAPIClientImpl) PatchAPIV1LayersByID(ctx context.Context, id stri
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}"
+	pathTemplate := "/api/v1/jobs/{id}/cancel"
 	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, JobBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1181,9 +1395,9 @@ func (c *PlatGeoAPIClientImpl) PatchAPIV1LayersByID(ctx context.Context, id stri
 	var reqBody io.Reader
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "PATCH", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, JobBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1192,26 +1406,26 @@ func (c *PlatGeoAPIClientImpl) PatchAPIV1LayersByID(ctx context.Context, id stri
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, JobBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, JobBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result LayerBody
+	var result JobBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, JobBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// PostAPIV1LayersByIDDuplicate calls the POST /api/v1/layers/{id}/duplicate endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDDuplicate(ctx context.Context, id string, body DuplicateInput, opts ...Option) (*http.Response, CreatedLayerBody, error) {
+// PostAPIV1JobsByIDRetry calls the POST /api/v1/jobs/{id}/retry endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1JobsByIDRetry(ctx context.Context, id string, opts ...Option) (*http.Response, JobBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1219,12 +1433,12 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDDuplicate(ctx context.Context,
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}/duplicate"
+	pathTemplate := "/api/v1/jobs/{id}/retry"
 	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, JobBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1232,47 +1446,39 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDDuplicate(ctx context.Context,
 
 	// Prepare request body
 	var reqBody io.Reader
-	jsonData, err := json.Marshal(body)
-	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, JobBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
-	if reqBody != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
 	reqOpts.applyHeaders(req)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, CreatedLayerBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, JobBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, CreatedLayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, JobBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result CreatedLayerBody
+	var result JobBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, CreatedLayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, JobBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// PostAPIV1LayersByIDPublish calls the POST /api/v1/layers/{id}/publish endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDPublish(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
+// GetAPIV1Layers calls the GET /api/v1/layers endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1Layers(ctx context.Context, opts ...Option) (*http.Response, map[string]any, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1280,12 +1486,11 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDPublish(ctx context.Context, i
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}/publish"
-	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+	pathTemplate := "/api/v1/layers"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1295,9 +1500,9 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDPublish(ctx context.Context, i
 	var reqBody io.Reader
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1306,26 +1511,26 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDPublish(ctx context.Context, i
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result LayerBody
+	var result map[string]any
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// ListAPIV1LayersByIDStyles calls the GET /api/v1/layers/{id}/styles endpoint
-func (c *PlatGeoAPIClientImpl) ListAPIV1LayersByIDStyles(ctx context.Context, id string, opts ...Option) (*http.Response, []Style, error) {
+// PostAPIV1Layers calls the POST /api/v1/layers endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1Layers(ctx context.Context, body LayerConfig, opts ...Option) (*http.Response, CreatedLayerBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1333,12 +1538,11 @@ func (c *PlatGeoAPIClientImpl) ListAPIV1LayersByIDStyles(ctx context.Context, id
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}/styles"
-	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+	pathTemplate := "/api/v1/layers"
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid URL: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1346,39 +1550,47 @@ func (c *PlatGeoAPIClientImpl) ListAPIV1LayersByIDStyles(ctx context.Context, id
 
 	// Prepare request body
 	var reqBody io.Reader
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, CreatedLayerBody{}, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	reqOpts.applyHeaders(req)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("request failed: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, CreatedLayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result []Style
+	var result CreatedLayerBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, nil, fmt.Errorf("failed to decode response: %w", err)
+		return resp, CreatedLayerBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// PostAPIV1LayersByIDStyles calls the POST /api/v1/layers/{id}/styles endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDStyles(ctx context.Context, id string, body Style, opts ...Option) (*http.Response, Style, error) {
+// GetAPIV1LayersByID calls the GET /api/v1/layers/{id} endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1386,12 +1598,12 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDStyles(ctx context.Context, id
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}/styles"
+	pathTemplate := "/api/v1/layers/{id}"
 	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, Style{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1399,47 +1611,39 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDStyles(ctx context.Context, id
 
 	// Prepare request body
 	var reqBody io.Reader
-	jsonData, err := json.Marshal(body)
-	if err != nil {
-		return nil, Style{}, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, Style{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
-	if reqBody != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
 	reqOpts.applyHeaders(req)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, Style{}, fmt.Errorf("request failed: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, Style{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result Style
+	var result LayerBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, Style{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// DeleteAPIV1LayersByIDStylesByStyleID calls the DELETE /api/v1/layers/{id}/styles/{styleId} endpoint
-func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByIDStylesByStyleID(ctx context.Context, id string, styleID string, opts ...Option) (*http.Response, MessageBody, error) {
+// PutAPIV1LayersByID calls the PUT /api/v1/layers/{id} endpoint
+func (c *PlatGeoAPIClientImpl) PutAPIV1LayersByID(ctx context.Context, id string, body LayerConfig, opts ...Option) (*http.Response, LayerBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1447,13 +1651,12 @@ func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByIDStylesByStyleID(ctx context.
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}/styles/{styleId}"
+	pathTemplate := "/api/v1/layers/{id}"
 	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
-	pathTemplate = strings.ReplaceAll(pathTemplate, "{styleId}", url.PathEscape(styleID))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, MessageBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1461,14 +1664,75 @@ func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByIDStylesByStyleID(ctx context.
 
 	// Prepare request body
 	var reqBody io.Reader
-
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "DELETE", u.String(), reqBody)
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, MessageBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("failed to marshal request body: %w", err)
 	}
+	reqBody = bytes.NewReader(jsonData)
 
-	// Set content type and apply custom headers
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "PUT", u.String(), reqBody)
+	if err != nil {
+		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result LayerBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// DeleteAPIV1LayersByID calls the DELETE /api/v1/layers/{id} endpoint
+func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, MessageBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/layers/{id}"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "DELETE", u.String(), reqBody)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
 	reqOpts.applyHeaders(req)
 
 	// Execute request
@@ -1492,8 +1756,8 @@ func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByIDStylesByStyleID(ctx context.
 	return resp, result, nil
 }
 
-// PostAPIV1LayersByIDUnpublish calls the POST /api/v1/layers/{id}/unpublish endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDUnpublish(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
+// PatchAPIV1LayersByID calls the PATCH /api/v1/layers/{id} endpoint
+func (c *PlatGeoAPIClientImpl) PatchAPIV1LayersByID(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1501,7 +1765,7 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDUnpublish(ctx context.Context,
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/layers/{id}/unpublish"
+	pathTemplate := "/api/v1/layers/{id}"
 	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
@@ -1516,7 +1780,7 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDUnpublish(ctx context.Context,
 	var reqBody io.Reader
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "PATCH", u.String(), reqBody)
 	if err != nil {
 		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1545,8 +1809,8 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDUnpublish(ctx context.Context,
 	return resp, result, nil
 }
 
-// PostAPIV1Query calls the POST /api/v1/query endpoint
-func (c *PlatGeoAPIClientImpl) PostAPIV1Query(ctx context.Context, body PostAPIV1QueryRequest, opts ...Option) (*http.Response, QueryBody, error) {
+// PostAPIV1LayersByIDDuplicate calls the POST /api/v1/layers/{id}/duplicate endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDDuplicate(ctx context.Context, id string, body DuplicateInput, opts ...Option) (*http.Response, CreatedLayerBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1554,11 +1818,12 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1Query(ctx context.Context, body PostAPIV
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/query"
+	pathTemplate := "/api/v1/layers/{id}/duplicate"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, QueryBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1568,14 +1833,14 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1Query(ctx context.Context, body PostAPIV
 	var reqBody io.Reader
 	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, QueryBody{}, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, QueryBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1587,26 +1852,26 @@ func (c *PlatGeoAPIClientImpl) PostAPIV1Query(ctx context.Context, body PostAPIV
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, QueryBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, CreatedLayerBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, QueryBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, CreatedLayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result QueryBody
+	var result CreatedLayerBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, QueryBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, CreatedLayerBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// GetAPIV1Sources calls the GET /api/v1/sources endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1Sources(ctx context.Context, opts ...Option) (*http.Response, PageBodySourceFile, error) {
+// PostAPIV1LayersByIDPublish calls the POST /api/v1/layers/{id}/publish endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDPublish(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1614,11 +1879,12 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Sources(ctx context.Context, opts ...Opti
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/sources"
+	pathTemplate := "/api/v1/layers/{id}/publish"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, PageBodySourceFile{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1628,9 +1894,9 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Sources(ctx context.Context, opts ...Opti
 	var reqBody io.Reader
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, PageBodySourceFile{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1639,26 +1905,26 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Sources(ctx context.Context, opts ...Opti
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, PageBodySourceFile{}, fmt.Errorf("request failed: %w", err)
+		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, PageBodySourceFile{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result PageBodySourceFile
+	var result LayerBody
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, PageBodySourceFile{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// GetAPIV1Tables calls the GET /api/v1/tables endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1Tables(ctx context.Context, opts ...Option) (*http.Response, TablesBody, error) {
+// ListAPIV1LayersByIDStyles calls the GET /api/v1/layers/{id}/styles endpoint
+func (c *PlatGeoAPIClientImpl) ListAPIV1LayersByIDStyles(ctx context.Context, id string, opts ...Option) (*http.Response, []Style, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1666,11 +1932,12 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Tables(ctx context.Context, opts ...Optio
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/tables"
+	pathTemplate := "/api/v1/layers/{id}/styles"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, TablesBody{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1682,7 +1949,7 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Tables(ctx context.Context, opts ...Optio
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
 	if err != nil {
-		return nil, TablesBody{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
@@ -1691,26 +1958,26 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Tables(ctx context.Context, opts ...Optio
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, TablesBody{}, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, TablesBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result TablesBody
+	var result []Style
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, TablesBody{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil
 }
 
-// GetAPIV1Tiles calls the GET /api/v1/tiles endpoint
-func (c *PlatGeoAPIClientImpl) GetAPIV1Tiles(ctx context.Context, opts ...Option) (*http.Response, PageBodyTileFile, error) {
+// PostAPIV1LayersByIDStyles calls the POST /api/v1/layers/{id}/styles endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDStyles(ctx context.Context, id string, body Style, opts ...Option) (*http.Response, Style, error) {
 	// Apply options
 	reqOpts := &RequestOptions{}
 	for _, opt := range opts {
@@ -1718,11 +1985,12 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Tiles(ctx context.Context, opts ...Option
 	}
 
 	// Build URL with path parameters
-	pathTemplate := "/api/v1/tiles"
+	pathTemplate := "/api/v1/layers/{id}/styles"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
 
 	u, err := url.Parse(c.baseURL + pathTemplate)
 	if err != nil {
-		return nil, PageBodyTileFile{}, fmt.Errorf("invalid URL: %w", err)
+		return nil, Style{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Apply query parameters
@@ -1730,32 +1998,795 @@ func (c *PlatGeoAPIClientImpl) GetAPIV1Tiles(ctx context.Context, opts ...Option
 
 	// Prepare request body
 	var reqBody io.Reader
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, Style{}, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	reqBody = bytes.NewReader(jsonData)
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
 	if err != nil {
-		return nil, PageBodyTileFile{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, Style{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set content type and apply custom headers
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	reqOpts.applyHeaders(req)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, PageBodyTileFile{}, fmt.Errorf("request failed: %w", err)
+		return nil, Style{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return resp, PageBodyTileFile{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return resp, Style{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 	// Parse response body
-	var result PageBodyTileFile
+	var result Style
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return resp, PageBodyTileFile{}, fmt.Errorf("failed to decode response: %w", err)
+		return resp, Style{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// DeleteAPIV1LayersByIDStylesByStyleID calls the DELETE /api/v1/layers/{id}/styles/{styleId} endpoint
+func (c *PlatGeoAPIClientImpl) DeleteAPIV1LayersByIDStylesByStyleID(ctx context.Context, id string, styleID string, opts ...Option) (*http.Response, MessageBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/layers/{id}/styles/{styleId}"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{styleId}", url.PathEscape(styleID))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "DELETE", u.String(), reqBody)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, MessageBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result MessageBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, MessageBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// PostAPIV1LayersByIDUnpublish calls the POST /api/v1/layers/{id}/unpublish endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1LayersByIDUnpublish(ctx context.Context, id string, opts ...Option) (*http.Response, LayerBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/layers/{id}/unpublish"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{id}", url.PathEscape(id))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, LayerBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	if err != nil {
+		return nil, LayerBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, LayerBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, LayerBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result LayerBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, LayerBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// PostAPIV1Query calls the POST /api/v1/query endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1Query(ctx context.Context, body PostAPIV1QueryRequest, opts ...Option) (*http.Response, QueryBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/query"
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, QueryBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, QueryBody{}, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	reqBody = bytes.NewReader(jsonData)
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	if err != nil {
+		return nil, QueryBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, QueryBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, QueryBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result QueryBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, QueryBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// GetAPIV1Sources calls the GET /api/v1/sources endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1Sources(ctx context.Context, opts ...Option) (*http.Response, PageBodySourceFile, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/sources"
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, PageBodySourceFile{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	if err != nil {
+		return nil, PageBodySourceFile{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, PageBodySourceFile{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, PageBodySourceFile{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result PageBodySourceFile
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, PageBodySourceFile{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// GetAPIV1Tables calls the GET /api/v1/tables endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1Tables(ctx context.Context, opts ...Option) (*http.Response, TablesBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tables"
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TablesBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	if err != nil {
+		return nil, TablesBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TablesBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TablesBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TablesBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TablesBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// GetAPIV1Tiles calls the GET /api/v1/tiles endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1Tiles(ctx context.Context, opts ...Option) (*http.Response, PageBodyTileFile, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles"
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, PageBodyTileFile{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	if err != nil {
+		return nil, PageBodyTileFile{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, PageBodyTileFile{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, PageBodyTileFile{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result PageBodyTileFile
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, PageBodyTileFile{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// PostAPIV1Tiles calls the POST /api/v1/tiles endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1Tiles(ctx context.Context, opts ...Option) (*http.Response, TileFileBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles"
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TileFileBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TileFileBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TileFileBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// GetAPIV1TilesByName calls the GET /api/v1/tiles/{name} endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1TilesByName(ctx context.Context, name string, opts ...Option) (*http.Response, TileFileBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TileFileBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TileFileBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TileFileBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// DeleteAPIV1TilesByName calls the DELETE /api/v1/tiles/{name} endpoint
+func (c *PlatGeoAPIClientImpl) DeleteAPIV1TilesByName(ctx context.Context, name string, opts ...Option) (*http.Response, MessageBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "DELETE", u.String(), reqBody)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, MessageBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, MessageBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result MessageBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, MessageBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// PostAPIV1TilesByNameRename calls the POST /api/v1/tiles/{name}/rename endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1TilesByNameRename(ctx context.Context, name string, body RenameTileInput, opts ...Option) (*http.Response, TileFileBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}/rename"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	reqBody = bytes.NewReader(jsonData)
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TileFileBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TileFileBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TileFileBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TileFileBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// GetAPIV1TilesByNameTilejsonJSON calls the GET /api/v1/tiles/{name}/tilejson.json endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1TilesByNameTilejsonJSON(ctx context.Context, name string, opts ...Option) (*http.Response, TileJSONBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}/tilejson.json"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TileJSONBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	if err != nil {
+		return nil, TileJSONBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TileJSONBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TileJSONBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TileJSONBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TileJSONBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// PostAPIV1TilesByNameVerify calls the POST /api/v1/tiles/{name}/verify endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1TilesByNameVerify(ctx context.Context, name string, opts ...Option) (*http.Response, TileVerificationBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}/verify"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TileVerificationBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	if err != nil {
+		return nil, TileVerificationBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TileVerificationBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TileVerificationBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TileVerificationBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TileVerificationBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// GetAPIV1TilesByNameVersions calls the GET /api/v1/tiles/{name}/versions endpoint
+func (c *PlatGeoAPIClientImpl) GetAPIV1TilesByNameVersions(ctx context.Context, name string, opts ...Option) (*http.Response, PageBodyTileVersionBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}/versions"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, PageBodyTileVersionBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), reqBody)
+	if err != nil {
+		return nil, PageBodyTileVersionBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, PageBodyTileVersionBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, PageBodyTileVersionBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result PageBodyTileVersionBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, PageBodyTileVersionBody{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, result, nil
+}
+
+// PostAPIV1TilesByNameVersionsByVersionRollback calls the POST /api/v1/tiles/{name}/versions/{version}/rollback endpoint
+func (c *PlatGeoAPIClientImpl) PostAPIV1TilesByNameVersionsByVersionRollback(ctx context.Context, name string, version string, opts ...Option) (*http.Response, TileVersionBody, error) {
+	// Apply options
+	reqOpts := &RequestOptions{}
+	for _, opt := range opts {
+		opt(reqOpts)
+	}
+
+	// Build URL with path parameters
+	pathTemplate := "/api/v1/tiles/{name}/versions/{version}/rollback"
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{name}", url.PathEscape(name))
+	pathTemplate = strings.ReplaceAll(pathTemplate, "{version}", url.PathEscape(version))
+
+	u, err := url.Parse(c.baseURL + pathTemplate)
+	if err != nil {
+		return nil, TileVersionBody{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Apply query parameters
+	reqOpts.applyQueryParams(u)
+
+	// Prepare request body
+	var reqBody io.Reader
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), reqBody)
+	if err != nil {
+		return nil, TileVersionBody{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set content type and apply custom headers
+	reqOpts.applyHeaders(req)
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, TileVersionBody{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle error responses
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return resp, TileVersionBody{}, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+	// Parse response body
+	var result TileVersionBody
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, TileVersionBody{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return resp, result, nil